@@ -0,0 +1,239 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	omifier "github.com/belqlabs/omf-mib-ingester"
+)
+
+type command struct {
+	usage string
+	run   func(args []string) error
+}
+
+var commands = map[string]command{
+	"gogen": {
+		usage: "generate typed go structs for the tables and scalars of a module",
+		run:   run_gogen,
+	},
+	"lint": {
+		usage: "report smilint style problems found in a module",
+		run:   run_lint,
+	},
+	"protogen": {
+		usage: "generate a protobuf schema for the tables, scalars and notifications of a module",
+		run:   run_protogen,
+	},
+	"protoopts": {
+		usage: "write the omf_options.proto imported by every protogen schema",
+		run:   run_protoopts,
+	},
+}
+
+func print_usage() {
+	fmt.Fprintf(os.Stderr, "usage: omifier <command> [flags]\n\ncommands:\n")
+
+	var names []string
+
+	for name := range commands {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", name, commands[name].usage)
+	}
+}
+
+func open_output(path string) (io.Writer, func() error, error) {
+	if path == "" || path == "-" {
+		return os.Stdout, func() error { return nil }, nil
+	}
+
+	file, err := os.Create(path)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return file, file.Close, nil
+}
+
+// gosmi prints module load failures to stdout itself, where they would mix
+// with the generated output, so modules are loaded with stdout on stderr.
+func load_module[T any](load func(string, string) (T, error), path string, module_name string) (T, error) {
+	stdout := os.Stdout
+
+	os.Stdout = os.Stderr
+
+	defer func() { os.Stdout = stdout }()
+
+	return load(path, module_name)
+}
+
+func load_omf_module(path string, module_name string) (omifier.OMFModule, error) {
+	return omifier.GetOmfCommomStruct(path, module_name, false)
+}
+
+func run_package_generator(name string, args []string, write func(io.Writer, omifier.OMFModule, string) error) error {
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+
+	path := flags.String("path", ".", "directory containing the MIB files")
+
+	module_name := flags.String("module", "", "name of the MIB module to generate")
+
+	package_name := flags.String("package", "", "package name of the generated source, derived from the module name when empty")
+
+	output := flags.String("out", "", "output file, stdout when empty")
+
+	flags.Parse(args)
+
+	if *module_name == "" {
+		flags.Usage()
+
+		return fmt.Errorf("%s: -module is required", name)
+	}
+
+	mod, err := load_module(load_omf_module, *path, *module_name)
+
+	if err != nil {
+		return err
+	}
+
+	out, close_output, err := open_output(*output)
+
+	if err != nil {
+		return err
+	}
+
+	err = write(out, mod, *package_name)
+
+	if err != nil {
+		close_output()
+
+		return err
+	}
+
+	return close_output()
+}
+
+func run_gogen(args []string) error {
+	return run_package_generator("gogen", args, omifier.WriteGoSource)
+}
+
+func run_protogen(args []string) error {
+	return run_package_generator("protogen", args, omifier.WriteProtoSource)
+}
+
+func run_protoopts(args []string) error {
+	flags := flag.NewFlagSet("protoopts", flag.ExitOnError)
+
+	output := flags.String("out", "", "output file, stdout when empty")
+
+	flags.Parse(args)
+
+	out, close_output, err := open_output(*output)
+
+	if err != nil {
+		return err
+	}
+
+	err = omifier.WriteProtoOptions(out)
+
+	if err != nil {
+		close_output()
+
+		return err
+	}
+
+	return close_output()
+}
+
+func run_lint(args []string) error {
+	flags := flag.NewFlagSet("lint", flag.ExitOnError)
+
+	path := flags.String("path", ".", "directory containing the MIB files")
+
+	module_name := flags.String("module", "", "name of the MIB module to lint")
+
+	format := flags.String("format", "text", "report format, text or json")
+
+	output := flags.String("out", "", "output file, stdout when empty")
+
+	flags.Parse(args)
+
+	if *module_name == "" {
+		flags.Usage()
+
+		return fmt.Errorf("lint: -module is required")
+	}
+
+	write := omifier.WriteLintReportText
+
+	switch *format {
+	case "text":
+	case "json":
+		write = omifier.WriteLintReportJson
+	default:
+		return fmt.Errorf("lint: unknown format %q", *format)
+	}
+
+	report, err := load_module(omifier.GetOmfLintReport, *path, *module_name)
+
+	if err != nil {
+		return err
+	}
+
+	out, close_output, err := open_output(*output)
+
+	if err != nil {
+		return err
+	}
+
+	err = write(out, report)
+
+	if err != nil {
+		close_output()
+
+		return err
+	}
+
+	err = close_output()
+
+	if err != nil {
+		return err
+	}
+
+	if errors := report.Count(omifier.OMFLintError); errors > 0 {
+		return fmt.Errorf("lint: %s has %d errors", report.Module, errors)
+	}
+
+	return nil
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		print_usage()
+		os.Exit(2)
+	}
+
+	cmd, found := commands[os.Args[1]]
+
+	if !found {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", os.Args[1])
+		print_usage()
+		os.Exit(2)
+	}
+
+	err := cmd.run(os.Args[2:])
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testdata_path = "../../testdata"
+
+func capture_stdout(t *testing.T, run func() error) (string, error) {
+	t.Helper()
+
+	read, write, err := os.Pipe()
+
+	if err != nil {
+		t.Fatalf("os.Pipe: %s", err)
+	}
+
+	stdout := os.Stdout
+
+	os.Stdout = write
+
+	run_err := run()
+
+	os.Stdout = stdout
+
+	write.Close()
+
+	captured, err := io.ReadAll(read)
+
+	if err != nil {
+		t.Fatalf("reading stdout: %s", err)
+	}
+
+	return string(captured), run_err
+}
+
+func TestGeneratorsKeepLoadErrorsOffStdout(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func([]string) error
+	}{
+		{"gogen", run_gogen},
+		{"protogen", run_protogen},
+		{"lint", run_lint},
+	}
+
+	for _, tt := range tests {
+		stdout, err := capture_stdout(t, func() error {
+			return tt.run([]string{"-path", testdata_path, "-module", "MISSING-MIB"})
+		})
+
+		if err == nil || !strings.Contains(err.Error(), "MISSING-MIB") {
+			t.Errorf("%s: error = %v, want one naming MISSING-MIB", tt.name, err)
+		}
+
+		if stdout != "" {
+			t.Errorf("%s: wrote %q to stdout", tt.name, stdout)
+		}
+	}
+}
+
+func TestGogenWritesOnlySource(t *testing.T) {
+	stdout, err := capture_stdout(t, func() error {
+		return run_gogen([]string{"-path", testdata_path, "-module", "ACME-TEST-MIB"})
+	})
+
+	if err != nil {
+		t.Fatalf("gogen: %s", err)
+	}
+
+	_, err = parser.ParseFile(token.NewFileSet(), "acme.go", stdout, 0)
+
+	if err != nil {
+		t.Errorf("gogen stdout is not go source: %s", err)
+	}
+
+	out := filepath.Join(t.TempDir(), "acme.proto")
+
+	err = run_protogen([]string{"-path", testdata_path, "-module", "ACME-TEST-MIB", "-out", out})
+
+	if err != nil {
+		t.Fatalf("protogen: %s", err)
+	}
+
+	source, err := os.ReadFile(out)
+
+	if err != nil || !strings.Contains(string(source), "\nsyntax = \"proto3\";\n") {
+		t.Errorf("protogen wrote %q (%v)", source, err)
+	}
+}
@@ -5,6 +5,8 @@ go 1.23.4
 require (
 	github.com/BurntSushi/toml v1.4.0
 	github.com/belqlabs/omf-gosmi v0.0.0-20250121232032-1501bd5db0a4
+	github.com/mattn/go-sqlite3 v1.14.33
+	gopkg.in/yaml.v2 v2.4.0
 )
 
 require github.com/alecthomas/participle v0.4.1 // indirect
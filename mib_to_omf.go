@@ -4,12 +4,17 @@ import (
 	"crypto/md5"
 	"fmt"
 	"hash/adler32"
+	"maps"
 	"os"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/belqlabs/omf-gosmi"
 	"github.com/belqlabs/omf-gosmi/models"
+	"github.com/belqlabs/omf-gosmi/parser"
 	gosmi_types "github.com/belqlabs/omf-gosmi/types"
 )
 
@@ -63,6 +68,28 @@ type OMFScalar struct {
 	OMFNode
 }
 
+type OMFGroup struct {
+	OMFNode
+	Members []OMFNode
+}
+
+type OMFComplianceItem struct {
+	Name        string
+	Description string `json:",omitempty"`
+}
+
+type OMFComplianceModule struct {
+	ModName         string `json:",omitempty"`
+	MandatoryGroups []string
+	Groups          []OMFComplianceItem `json:",omitempty"`
+	Objects         []OMFComplianceItem `json:",omitempty"`
+}
+
+type OMFCompliance struct {
+	OMFNode
+	Modules []OMFComplianceModule
+}
+
 type OMFImport struct {
 	ModName       string
 	ImportedNodes []string
@@ -82,6 +109,10 @@ type OMFModule struct {
 	ContactInfo        string
 	Description        string
 	Language           string
+	LanguageVersion    int
+	LastUpdated        time.Time
+	IdentityName       string
+	IdentityOid        string
 	Name               string
 	Organization       string
 	Path               string
@@ -94,13 +125,20 @@ type OMFModule struct {
 	TextualConventions []OMFTextualConvention
 	Tables             []OMFTable
 	Notifications      []OMFNotification
+	Groups             []OMFGroup
+	Compliances        []OMFCompliance
 	OtherNodes         []OMFNode
+	ParseBack          *OMFParseBackReport  `json:",omitempty" toml:",omitempty"`
+	Conversion         *OMFConversionReport `json:",omitempty" toml:",omitempty"`
 }
 
 type OMFTreeNode struct {
-	NodeOid  string
-	Node     OMFNode
-	Children map[string]*OMFTreeNode `json:",omitempty"`
+	NodeOid     string
+	Node        OMFNode
+	Synthesized bool            `json:",omitempty"`
+	DefinedBy   []string        `json:",omitempty"`
+	ImportedBy  []string        `json:",omitempty"`
+	Children    OMFTreeChildren `json:",omitempty"`
 }
 
 type OMFTree struct {
@@ -128,14 +166,18 @@ type OMFRepositoryType struct {
 }
 
 type OMFRepositoryModule struct {
-	ModuleName string
-	ModuleHash string
-	Nodes      []OMFRepositoryNode
-	Types      []OMFRepositoryType
+	ModuleName      string
+	ModuleHash      string
+	LanguageVersion int
+	LastUpdated     time.Time
+	IdentityName    string
+	IdentityOid     string
+	Nodes           []OMFRepositoryNode
+	Types           []OMFRepositoryType
 }
 
 type OMFTypeConstraint interface {
-	OMFModule | OMFTextualConvention | OMFRevision | OMFImport | OMFScalar | OMFNotification | OMFIndex | OMFTable | OMFNode | OMFType | OMFRange | OMFEnum
+	OMFModule | OMFTextualConvention | OMFRevision | OMFImport | OMFScalar | OMFNotification | OMFGroup | OMFCompliance | OMFIndex | OMFTable | OMFNode | OMFType | OMFRange | OMFEnum
 }
 
 func join_imports(imps []models.Import) map[string][]string {
@@ -155,20 +197,25 @@ func join_imports(imps []models.Import) map[string][]string {
 	return import_map
 }
 
-func init_gosmi(path string, module_name string) {
+func init_gosmi(path string, module_name string) error {
+	exit_gosmi()
+
 	gosmi.Init()
 
-	gosmi.AppendPath(path)
+	append_mib_path(path)
 
 	_, err := gosmi.LoadModule(module_name)
 	if err != nil {
-		fmt.Printf("Init Error: %s\n", err)
-		return
+		return fmt.Errorf("loading %s: %w", module_name, err)
 	}
+
+	return nil
 }
 
 func exit_gosmi() {
 	gosmi.Exit()
+
+	reset_parsed_module_sources()
 }
 
 func omfy_enum(en *models.Enum) OMFEnum {
@@ -254,11 +301,11 @@ func omfy_imports(imports []models.Import) []OMFImport {
 
 	import_map := join_imports(imports)
 
-	for mod_name, imported_members := range import_map {
+	for _, mod_name := range slices.Sorted(maps.Keys(import_map)) {
 
 		var members []string
 
-		for _, member_name := range imported_members {
+		for _, member_name := range import_map[mod_name] {
 
 			members = append(members, member_name)
 		}
@@ -325,6 +372,72 @@ func omfy_notification(nf *gosmi.Notification) OMFNotification {
 	}
 }
 
+func omfy_group(gr *gosmi.SmiNode) OMFGroup {
+	var omf_group_members []OMFNode
+
+	for _, member := range gr.GetNotificationObjects() {
+		omf_group_members = append(omf_group_members, omfy_node(&member))
+	}
+
+	return OMFGroup{
+		OMFNode: omfy_node(gr),
+		Members: omf_group_members,
+	}
+}
+
+func omfy_compliance_items_names(names []gosmi_types.SmiIdentifier) []string {
+	var items []string
+
+	for _, name := range names {
+		items = append(items, name.String())
+	}
+
+	return items
+}
+
+func omfy_compliance(cp *gosmi.SmiNode, parsed_module *parser.Module) OMFCompliance {
+	omf_compliance := OMFCompliance{
+		OMFNode: omfy_node(cp),
+	}
+
+	if parsed_module == nil {
+		return omf_compliance
+	}
+
+	for _, parsed_node := range parsed_module.Body.Nodes {
+		if parsed_node.Name.String() != cp.Name || parsed_node.ModuleCompliance == nil {
+			continue
+		}
+
+		for _, parsed_mod := range parsed_node.ModuleCompliance.Modules {
+			omf_compliance_module := OMFComplianceModule{
+				ModName:         string(parsed_mod.Name),
+				MandatoryGroups: omfy_compliance_items_names(parsed_mod.MandatoryGroups),
+			}
+
+			for _, item := range parsed_mod.Compliances {
+				if item.Group != nil {
+					omf_compliance_module.Groups = append(omf_compliance_module.Groups, OMFComplianceItem{
+						Name:        item.Group.Name.String(),
+						Description: item.Group.Description,
+					})
+				}
+
+				if item.Object != nil {
+					omf_compliance_module.Objects = append(omf_compliance_module.Objects, OMFComplianceItem{
+						Name:        item.Object.Name.String(),
+						Description: item.Object.Description,
+					})
+				}
+			}
+
+			omf_compliance.Modules = append(omf_compliance.Modules, omf_compliance_module)
+		}
+	}
+
+	return omf_compliance
+}
+
 func omfy_revisions(revs []models.Revision) []OMFRevision {
 	var revs_arr []OMFRevision
 
@@ -366,14 +479,171 @@ func collapse_map[T OMFTypeConstraint](mp map[string]T, order []string) []T {
 		return res
 	}
 
-	for _, entry := range mp {
-		res = append(res, entry)
+	// Without an order the entries are sorted by key, so a module always
+	// serializes the same way.
+	for _, key := range slices.Sorted(maps.Keys(mp)) {
+		res = append(res, mp[key])
 	}
 
 	return res
 }
 
-func generate_module_hash(description string, name string, revisions *[]OMFRevision) string {
+func omf_module_nodes(mod *OMFModule) []OMFNode {
+	var nodes []OMFNode
+
+	seen := make(map[string]bool)
+
+	append_node := func(nd OMFNode) {
+		if nd.Name == "" || seen[nd.Name] {
+			return
+		}
+
+		seen[nd.Name] = true
+
+		nodes = append(nodes, nd)
+	}
+
+	for _, nd := range mod.OtherNodes {
+		append_node(nd)
+	}
+
+	for _, sc := range mod.Scalars {
+		append_node(sc.OMFNode)
+	}
+
+	for _, tb := range mod.Tables {
+		append_node(tb.OMFNode)
+
+		append_node(tb.Entry)
+
+		for _, col := range tb.Columns {
+			append_node(col)
+		}
+	}
+
+	for _, nf := range mod.Notifications {
+		append_node(nf.OMFNode)
+	}
+
+	for _, gr := range mod.Groups {
+		append_node(gr.OMFNode)
+	}
+
+	for _, cp := range mod.Compliances {
+		append_node(cp.OMFNode)
+	}
+
+	return nodes
+}
+
+func omf_module_oid_names(mod *OMFModule) map[string]string {
+	oid_names := make(map[string]string)
+
+	for _, nd := range omf_module_nodes(mod) {
+		if smi_identifier_regex.MatchString(nd.Name) && nd.Oid != "" {
+			oid_names[nd.Oid] = nd.Name
+		}
+	}
+
+	return oid_names
+}
+
+func omf_scalar_parent(oid_names map[string]string, nd *OMFNode) (string, string) {
+	arcs := split_oid_arcs(nd.Oid)
+
+	if len(arcs) < 2 {
+		return "scalars", ""
+	}
+
+	parent_oid := strings.Join(arcs[:len(arcs)-1], ".")
+
+	if name, ok := oid_names[parent_oid]; ok {
+		return name, parent_oid
+	}
+
+	if well_known, ok := omf_well_known_nodes[parent_oid]; ok {
+		return well_known.Name, parent_oid
+	}
+
+	return "scalars", parent_oid
+}
+
+func omfy_module_identity(mod *gosmi.SmiModule) (string, string) {
+	identity_node, found := mod.GetIdentityNode()
+
+	if !found {
+		return "", ""
+	}
+
+	return identity_node.Name, identity_node.Oid.String()
+}
+
+// Sources are parsed once per loaded module. The cache goes with the gosmi
+// handle, as the next handle may find another file under the same path.
+var parsed_module_sources = struct {
+	sync.Mutex
+	modules map[string]*parser.Module
+}{modules: make(map[string]*parser.Module)}
+
+func reset_parsed_module_sources() {
+	parsed_module_sources.Lock()
+	defer parsed_module_sources.Unlock()
+
+	clear(parsed_module_sources.modules)
+}
+
+func parse_module_source(mod *gosmi.SmiModule) *parser.Module {
+	key := mod.Name + "\x00" + mod.Path
+
+	parsed_module_sources.Lock()
+	defer parsed_module_sources.Unlock()
+
+	parsed_module, ok := parsed_module_sources.modules[key]
+
+	if !ok {
+		parsed_module = read_module_source(mod.Path)
+
+		parsed_module_sources.modules[key] = parsed_module
+	}
+
+	return parsed_module
+}
+
+func read_module_source(path string) *parser.Module {
+	source, err := open_module_source(path)
+
+	if err != nil {
+		return nil
+	}
+
+	defer source.Close()
+
+	parsed_module, err := parser.Parse(source)
+
+	if err != nil {
+		return nil
+	}
+
+	return parsed_module
+}
+
+func read_module_last_updated(parsed_module *parser.Module, revisions *[]OMFRevision) time.Time {
+	if parsed_module != nil && parsed_module.Body.Identity != nil {
+		return parsed_module.Body.Identity.LastUpdated.ToTime()
+	}
+
+	last_updated := time.Time{}
+
+	for _, rev := range *revisions {
+		if rev.Date.After(last_updated) {
+			last_updated = rev.Date
+		}
+	}
+
+	return last_updated
+}
+
+func generate_module_hash(description string, name string, language_version int, last_updated time.Time, identity_oid string, revisions *[]OMFRevision) string {
 	rev_str := ""
 
 	for _, rev := range *revisions {
@@ -381,7 +651,7 @@ func generate_module_hash(description string, name string, revisions *[]OMFRevis
 		rev_str += rev.Date.String()
 	}
 
-	mod_hash_string := fmt.Sprintf("%s%s%s", description, name, rev_str)
+	mod_hash_string := fmt.Sprintf("%s%s%d%s%s%s", description, name, language_version, last_updated.String(), identity_oid, rev_str)
 
 	mod_hash := fmt.Sprintf("%x", md5.Sum([]byte(mod_hash_string)))
 
@@ -430,89 +700,110 @@ func append_textual_conventions(tcs []OMFTextualConvention, tc_map map[string]OM
 	return tc_map
 }
 
-func get_tree_node_from_node(nd *gosmi.SmiNode, visited map[string]bool) (OMFTreeNode, map[string]bool) {
-	omf_node := omfy_node(nd)
+func omf_node_identity(nd *gosmi.SmiNode) string {
+	return fmt.Sprintf("%s::%s", nd.GetModule().Name, nd.Name)
+}
 
-	new_tree_node := OMFTreeNode{
-		NodeOid:  omf_node.Oid,
-		Node:     omf_node,
-		Children: make(map[string]*OMFTreeNode),
-	}
+func set_tree_node(tree_node *OMFTreeNode, omf_node OMFNode) {
+	tree_node.Node = omf_node
 
-	node_sub_tree := nd.GetSubtree()
+	tree_node.NodeOid = omf_node.Oid
 
-	visited[nd.Name] = true
+	tree_node.Synthesized = false
+}
 
-	if len(node_sub_tree) <= 0 {
-		return new_tree_node, visited
-	}
+func fill_tree_node(tree_node *OMFTreeNode, nd *gosmi.SmiNode) {
+	set_tree_node(tree_node, omfy_node(nd))
+}
 
-	for _, internal_node := range node_sub_tree {
+func insert_nodes_in_tree(root *OMFTreeNode, root_oid gosmi_types.Oid, nds []gosmi.SmiNode, visited map[string]bool) {
+	for _, nd := range nds {
+		identity := omf_node_identity(&nd)
 
-		if visited[internal_node.Name] {
+		if visited[identity] {
 			continue
 		}
 
-		internal_node_sub_idx := internal_node.Oid[len(internal_node.Oid)-1]
-
-		new_node_sub_tree, new_visited := get_tree_node_from_node(&internal_node, visited)
+		if len(nd.Oid) <= len(root_oid) || !nd.Oid.ChildOf(root_oid) {
+			continue
+		}
 
-		new_tree_node.Children[fmt.Sprintf("%v", internal_node_sub_idx)] = &new_node_sub_tree
+		fill_tree_node(tree_node_at(nd.Oid, len(root_oid), root.Children, true), &nd)
 
-		visited = new_visited
+		visited[identity] = true
 	}
-
-	return new_tree_node, visited
 }
 
-func find_root_of_nodes_list(nds []gosmi.SmiNode) OMFNode {
-	smallest_oid_len := 123456789
+// Nodes under ccitt (zeroDotZero and the like) are skipped, as they would
+// otherwise leave no common prefix with the rest of the module. Should the
+// remaining nodes still share no arc, the top arc holding most of them is
+// used so the tree always has a root.
+func find_root_oid_of_nodes_list(nds []gosmi.SmiNode) gosmi_types.Oid {
+	var root_oid gosmi_types.Oid
 
-	node_closest_to_root := gosmi.SmiNode{}
+	arc_counts := make(map[gosmi_types.SmiSubId]int)
 
 	for _, nd := range nds {
-		if nd.Oid[0] == 0 {
+		if len(nd.Oid) == 0 || nd.Oid[0] == 0 {
 			continue
 		}
 
-		if nd.OidLen < smallest_oid_len {
-			smallest_oid_len = nd.OidLen
+		arc_counts[nd.Oid[0]]++
+
+		if root_oid == nil {
+			root_oid = nd.Oid
 
-			node_closest_to_root = nd
+			continue
 		}
+
+		common_len := 0
+
+		for common_len < len(root_oid) && common_len < len(nd.Oid) && root_oid[common_len] == nd.Oid[common_len] {
+			common_len++
+		}
+
+		root_oid = root_oid[:common_len]
 	}
 
-	return omfy_node(&node_closest_to_root)
+	if len(root_oid) == 0 && len(arc_counts) > 0 {
+		var top_arc gosmi_types.SmiSubId
+
+		for arc, count := range arc_counts {
+			if count > arc_counts[top_arc] || (count == arc_counts[top_arc] && arc < top_arc) {
+				top_arc = arc
+			}
+		}
+
+		return gosmi_types.Oid{top_arc}
+	}
+
+	return root_oid
 }
 
 func get_tree_by_node_collection(nodes []gosmi.SmiNode) OMFTreeNode {
-	root_of_nodes := find_root_of_nodes_list(nodes)
+	root_oid := find_root_oid_of_nodes_list(nodes)
 
 	visited := make(map[string]bool)
 
-	root_tree_node := OMFTreeNode{
-		NodeOid:  root_of_nodes.Oid,
-		Node:     root_of_nodes,
-		Children: make(map[string]*OMFTreeNode),
-	}
-
-	visited[root_of_nodes.Name] = true
+	root_tree_node := synthesize_tree_node(root_oid)
 
 	for _, node := range nodes {
-		if visited[node.Name] {
+		if len(node.Oid) == 0 || !node.Oid.Equals(root_oid) {
 			continue
 		}
 
-		node_sub_idx := node.Oid[len(node.Oid)-1]
+		fill_tree_node(root_tree_node, &node)
 
-		new_node, new_visited := get_tree_node_from_node(&node, visited)
+		visited[omf_node_identity(&node)] = true
 
-		root_tree_node.Children[fmt.Sprintf("%v", node_sub_idx)] = &new_node
+		insert_nodes_in_tree(root_tree_node, root_oid, node.GetSubtree(), visited)
 
-		visited = new_visited
+		break
 	}
 
-	return root_tree_node
+	insert_nodes_in_tree(root_tree_node, root_oid, nodes, visited)
+
+	return *root_tree_node
 }
 
 func create_omf_tree(mod *gosmi.SmiModule) (OMFTree, error) {
@@ -522,7 +813,11 @@ func create_omf_tree(mod *gosmi.SmiModule) (OMFTree, error) {
 
 	omf_imports := omfy_imports(mod.GetImports())
 
-	mod_hash := generate_module_hash(mod.Description, mod.Name, &omfied_revisions)
+	_, identity_oid := omfy_module_identity(mod)
+
+	last_updated := read_module_last_updated(parse_module_source(mod), &omfied_revisions)
+
+	mod_hash := generate_module_hash(mod.Description, mod.Name, int(mod.Language), last_updated, identity_oid, &omfied_revisions)
 
 	mod_tree := OMFTree{
 		ModuleHash:    mod_hash,
@@ -566,7 +861,17 @@ func omfy_module(mod *gosmi.SmiModule) OMFModule {
 
 	omf_other_nodes_map := make(map[string]OMFNode)
 
-	module_hash := generate_module_hash(mod.Description, mod.Name, &omfied_revisions)
+	omf_groups_map := make(map[string]OMFGroup)
+
+	omf_compliances_map := make(map[string]OMFCompliance)
+
+	parsed_module := parse_module_source(mod)
+
+	identity_name, identity_oid := omfy_module_identity(mod)
+
+	last_updated := read_module_last_updated(parsed_module, &omfied_revisions)
+
+	module_hash := generate_module_hash(mod.Description, mod.Name, int(mod.Language), last_updated, identity_oid, &omfied_revisions)
 
 	var omf_tables_map_order []string
 
@@ -578,6 +883,10 @@ func omfy_module(mod *gosmi.SmiModule) OMFModule {
 
 	var omf_other_nodes_map_order []string
 
+	var omf_groups_map_order []string
+
+	var omf_compliances_map_order []string
+
 	omf_textual_conventions := make(map[string]OMFTextualConvention)
 
 	for _, n := range nodes {
@@ -629,6 +938,26 @@ func omfy_module(mod *gosmi.SmiModule) OMFModule {
 			continue
 		}
 
+		if n.Kind == gosmi_types.NodeGroup {
+			omfied_group := omfy_group(&n)
+
+			omf_groups_map[omfied_group.Name] = omfied_group
+
+			omf_groups_map_order = append(omf_groups_map_order, omfied_group.Name)
+
+			continue
+		}
+
+		if n.Kind == gosmi_types.NodeCompliance {
+			omfied_compliance := omfy_compliance(&n, parsed_module)
+
+			omf_compliances_map[omfied_compliance.Name] = omfied_compliance
+
+			omf_compliances_map_order = append(omf_compliances_map_order, omfied_compliance.Name)
+
+			continue
+		}
+
 		if n.Kind == gosmi_types.NodeRow {
 			omfied_row := omfy_node(&n)
 
@@ -677,6 +1006,10 @@ func omfy_module(mod *gosmi.SmiModule) OMFModule {
 		ContactInfo:        mod.ContactInfo,
 		Description:        mod.Description,
 		Language:           mod.Language.String(),
+		LanguageVersion:    int(mod.Language),
+		LastUpdated:        last_updated,
+		IdentityName:       identity_name,
+		IdentityOid:        identity_oid,
 		Name:               mod.Name,
 		Organization:       mod.Organization,
 		Path:               mod.Path,
@@ -689,60 +1022,108 @@ func omfy_module(mod *gosmi.SmiModule) OMFModule {
 		Types:              collapse_map(omf_types_map, omf_types_map_order),
 		Tables:             collapse_map(omf_tables_map, omf_tables_map_order),
 		Notifications:      collapse_map(omf_notifications_map, omf_notifications_map_order),
+		Groups:             collapse_map(omf_groups_map, omf_groups_map_order),
+		Compliances:        collapse_map(omf_compliances_map, omf_compliances_map_order),
 		OtherNodes:         collapse_map(omf_other_nodes_map, omf_other_nodes_map_order),
 	}
 }
 
-func module_trees(module_name string) {
+func module_trees(path string, module_name string) error {
+	err := init_gosmi(path, module_name)
+
+	if err != nil {
+		exit_gosmi()
+
+		return err
+	}
+
+	defer exit_gosmi()
+
 	m, err := gosmi.GetModule(module_name)
+
 	if err != nil {
-		return
+		return err
 	}
 
 	omf_module := omfy_module(&m)
 
-	tl, err2 := toml.Marshal(omf_module)
+	tl, err := toml.Marshal(omf_module)
 
-	if err2 != nil {
-		fmt.Printf("%s", err2)
-		return
+	if err != nil {
+		return fmt.Errorf("marshal %s: %w", module_name, err)
 	}
 
-	os.WriteFile("teste3.toml", tl, 0666)
+	return os.WriteFile("teste3.toml", tl, 0666)
 }
 
+// Errors are returned rather than printed, since the generators in
+// cmd/omifier write their output to stdout.
 func GetOmfCommomStruct(path string, module_name string, parseBack bool) (OMFModule, error) {
+	err := init_gosmi(path, module_name)
+
+	if err != nil {
+		exit_gosmi()
 
-	init_gosmi(path, module_name)
+		return OMFModule{Name: module_name}, err
+	}
 
 	m, err := gosmi.GetModule(module_name)
 
+	if err != nil {
+		exit_gosmi()
+
+		return OMFModule{Name: module_name}, err
+	}
+
 	omf_module := omfy_module(&m)
 
+	exit_gosmi()
+
+	if !parseBack {
+		return omf_module, nil
+	}
+
+	parse_back_report, err := parse_back_omf_module(path, &omf_module)
+
 	if err != nil {
-		fmt.Printf("ModuleTrees Error: %s\n", err)
-		return omf_module, err
+		return omf_module, fmt.Errorf("parse back: %w", err)
 	}
 
-	exit_gosmi()
+	omf_module.ParseBack = &parse_back_report
+
 	return omf_module, nil
 }
 
 func GetOmfRepositoryModule(path string, module_name string) (OMFRepositoryModule, error) {
-	init_gosmi(path, module_name)
+	err := init_gosmi(path, module_name)
+
+	if err != nil {
+		exit_gosmi()
+
+		return OMFRepositoryModule{ModuleName: module_name}, err
+	}
+
+	defer exit_gosmi()
 
 	m, err := gosmi.GetModule(module_name)
 
+	if err != nil {
+		return OMFRepositoryModule{ModuleName: module_name}, fmt.Errorf("repository module %s: %w", module_name, err)
+	}
+
 	mod_rev := omfy_revisions(m.GetRevisions())
 
-	omf_repo_module := OMFRepositoryModule{
-		ModuleName: m.Name,
-		ModuleHash: generate_module_hash(m.Description, m.Name, &mod_rev),
-	}
+	identity_name, identity_oid := omfy_module_identity(&m)
 
-	if err != nil {
-		fmt.Printf("ModuleTrees Error: %s\n", err)
-		return omf_repo_module, err
+	last_updated := read_module_last_updated(parse_module_source(&m), &mod_rev)
+
+	omf_repo_module := OMFRepositoryModule{
+		ModuleName:      m.Name,
+		ModuleHash:      generate_module_hash(m.Description, m.Name, int(m.Language), last_updated, identity_oid, &mod_rev),
+		LanguageVersion: int(m.Language),
+		LastUpdated:     last_updated,
+		IdentityName:    identity_name,
+		IdentityOid:     identity_oid,
 	}
 
 	module_nodes := []OMFRepositoryNode{}
@@ -768,31 +1149,35 @@ func GetOmfRepositoryModule(path string, module_name string) (OMFRepositoryModul
 		module_types = append(module_types, new_repo_mod_type)
 	}
 
-	exit_gosmi()
+	omf_repo_module.Nodes = module_nodes
+
+	omf_repo_module.Types = module_types
 
 	return omf_repo_module, nil
 }
 
 func GetOmfModuleTree(path string, module_name string) (OMFTree, error) {
-	init_gosmi(path, module_name)
-
-	m, err := gosmi.GetModule(module_name)
+	err := init_gosmi(path, module_name)
 
 	if err != nil {
-		fmt.Printf("GetOmfModuleTree Error: %v\n", err)
+		exit_gosmi()
 
 		return OMFTree{}, err
 	}
 
-	omf_tree, err2 := create_omf_tree(&m)
+	defer exit_gosmi()
 
-	if err2 != nil {
-		fmt.Printf("create_omf_tree Error: %v\n", err2)
+	m, err := gosmi.GetModule(module_name)
 
-		return OMFTree{}, err
+	if err != nil {
+		return OMFTree{}, fmt.Errorf("module tree %s: %w", module_name, err)
 	}
 
-	exit_gosmi()
+	omf_tree, err := create_omf_tree(&m)
+
+	if err != nil {
+		return OMFTree{}, fmt.Errorf("module tree %s: %w", module_name, err)
+	}
 
 	return omf_tree, nil
 }
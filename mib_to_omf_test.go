@@ -0,0 +1,155 @@
+package omifier
+
+import (
+	"testing"
+	"time"
+
+	"github.com/belqlabs/omf-gosmi"
+)
+
+func TestModuleTreeRootOid(t *testing.T) {
+	tests := []struct {
+		module    string
+		root_oid  string
+		root_name string
+		leaf_oid  string
+	}{
+		{"ACME-TEST-MIB", "1.3.6.1.4.1.99999", "acmeTestMIB", "1.3.6.1.4.1.99999.1.10.1.2"},
+		{"CCITT-MIB", "1.3.6.1.4.1.77779", "ccittEnterprise", "1.3.6.1.4.1.77779.2"},
+		{"SPLIT-MIB", "1", "iso", "1.3.6.1.4.1.77780.1"},
+	}
+
+	for _, tt := range tests {
+		tree, err := GetOmfModuleTree(omf_testdata_path, tt.module)
+
+		if err != nil {
+			t.Fatalf("GetOmfModuleTree(%s): %s", tt.module, err)
+		}
+
+		if tree.RootNode == nil || tree.RootNode.NodeOid != tt.root_oid || tree.RootNode.Node.Name != tt.root_name {
+			t.Errorf("%s: root is %+v, want %s (%s)", tt.module, tree.RootNode, tt.root_name, tt.root_oid)
+
+			continue
+		}
+
+		if tree.Find(tt.leaf_oid) == nil {
+			t.Errorf("%s: Find(%s) = nil", tt.module, tt.leaf_oid)
+		}
+	}
+}
+
+func TestModuleIdentityAndLastUpdated(t *testing.T) {
+	tests := []struct {
+		module        string
+		identity_name string
+		identity_oid  string
+		last_updated  string
+	}{
+		{"ACME-TEST-MIB", "acmeTestMIB", "1.3.6.1.4.1.99999", "2024-01-15T00:00:00Z"},
+		{"GAP-MIB", "", "", "0001-01-01T00:00:00Z"},
+	}
+
+	for _, tt := range tests {
+		mod, err := GetOmfCommomStruct(omf_testdata_path, tt.module, false)
+
+		if err != nil {
+			t.Fatalf("GetOmfCommomStruct(%s): %s", tt.module, err)
+		}
+
+		if mod.IdentityName != tt.identity_name || mod.IdentityOid != tt.identity_oid {
+			t.Errorf("%s: identity is %s (%s), want %s (%s)", tt.module, mod.IdentityName, mod.IdentityOid, tt.identity_name, tt.identity_oid)
+		}
+
+		if last_updated := mod.LastUpdated.UTC().Format(time.RFC3339); last_updated != tt.last_updated {
+			t.Errorf("%s: LastUpdated is %s, want %s", tt.module, last_updated, tt.last_updated)
+		}
+	}
+}
+
+func TestParseModuleSourceIsCached(t *testing.T) {
+	err := init_gosmi(omf_testdata_path, "ACME-TEST-MIB")
+
+	if err != nil {
+		exit_gosmi()
+
+		t.Fatalf("init_gosmi: %s", err)
+	}
+
+	mod, err := gosmi.GetModule("ACME-TEST-MIB")
+
+	if err != nil {
+		exit_gosmi()
+
+		t.Fatalf("GetModule: %s", err)
+	}
+
+	first := parse_module_source(&mod)
+
+	second := parse_module_source(&mod)
+
+	exit_gosmi()
+
+	if first == nil || first != second {
+		t.Fatalf("parse_module_source parsed ACME-TEST-MIB twice or not at all")
+	}
+
+	if len(parsed_module_sources.modules) != 0 {
+		t.Errorf("exit_gosmi left %d parsed sources cached", len(parsed_module_sources.modules))
+	}
+}
+
+func TestGetOmfModuleTreeErrors(t *testing.T) {
+	for _, module_name := range []string{"NO-SUCH-MIB", "ACME-TEST-MIB"} {
+		path := omf_testdata_path
+
+		if module_name == "ACME-TEST-MIB" {
+			path = t.TempDir()
+		}
+
+		if _, err := GetOmfModuleTree(path, module_name); err == nil {
+			t.Errorf("GetOmfModuleTree(%s, %s) returned no error", path, module_name)
+		}
+
+		if _, err := GetOmfRepositoryModule(path, module_name); err == nil {
+			t.Errorf("GetOmfRepositoryModule(%s, %s) returned no error", path, module_name)
+		}
+	}
+}
+
+func TestModuleLanguageVersion(t *testing.T) {
+	tests := []struct {
+		module           string
+		language_version int
+	}{
+		{"ACME-TEST-MIB", 2},
+		{"ACME-V1-MIB", 1},
+	}
+
+	for _, tt := range tests {
+		mod, err := GetOmfCommomStruct(omf_testdata_path, tt.module, false)
+
+		if err != nil {
+			t.Fatalf("GetOmfCommomStruct(%s): %s", tt.module, err)
+		}
+
+		repo_mod, err := GetOmfRepositoryModule(omf_testdata_path, tt.module)
+
+		if err != nil {
+			t.Fatalf("GetOmfRepositoryModule(%s): %s", tt.module, err)
+		}
+
+		if mod.LanguageVersion != tt.language_version || repo_mod.LanguageVersion != tt.language_version {
+			t.Errorf("%s: language version is %d, summary %d, want %d", tt.module, mod.LanguageVersion, repo_mod.LanguageVersion, tt.language_version)
+		}
+
+		if repo_mod.ModuleHash != mod.ModuleHash {
+			t.Errorf("%s: summary hash %s differs from module hash %s", tt.module, repo_mod.ModuleHash, mod.ModuleHash)
+		}
+	}
+
+	revisions := []OMFRevision{}
+
+	if generate_module_hash("", "A-MIB", 1, time.Time{}, "", &revisions) == generate_module_hash("", "A-MIB", 2, time.Time{}, "", &revisions) {
+		t.Errorf("the module hash does not cover the language version")
+	}
+}
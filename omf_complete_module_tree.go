@@ -1,21 +1,39 @@
 package omifier
 
 import (
+	"errors"
 	"fmt"
 	gosmi "github.com/belqlabs/omf-gosmi"
 	gosmi_types "github.com/belqlabs/omf-gosmi/types"
+	"slices"
 )
 
+var ErrUnresolvedImport = errors.New("unresolved import")
+
+type OMFUnresolvedImport struct {
+	Module         string
+	ImportedModule string
+	Error          string
+}
+
+type OMFCompleteTreeOptions struct {
+	ConflictPolicy          OMFConflictPolicy
+	FailOnUnresolvedImports bool
+}
+
 type OMFCompleteModuleTree struct {
-	Hash         string
-	Contact      string
-	Description  string
-	Language     string
-	Name         string
-	Organization string
-	Path         string
-	Reference    string
-	Tree         map[string]*OMFTreeNode
+	Hash              string
+	Contact           string
+	Description       string
+	Language          string
+	Name              string
+	Organization      string
+	Path              string
+	Reference         string
+	ConflictPolicy    OMFConflictPolicy     `json:",omitempty"`
+	Conflicts         []OMFTreeConflict     `json:",omitempty"`
+	UnresolvedImports []OMFUnresolvedImport `json:",omitempty"`
+	Tree              OMFTreeChildren
 }
 
 func append_module(module_name string) error {
@@ -24,51 +42,95 @@ func append_module(module_name string) error {
 	return err
 }
 
-func get_leaf_by_oid(oid gosmi_types.Oid, node_map map[string]*OMFTreeNode) *OMFTreeNode {
-	if len(oid) == 1 {
-		_, leaf_exists := node_map[fmt.Sprintf("%v", oid)]
+// Imports are followed breadth first; a module is visited once, which also
+// ends import cycles.
+func load_module_imports(module_names []string) []OMFUnresolvedImport {
+	var unresolved []OMFUnresolvedImport
 
-		if !leaf_exists {
-			node_map[fmt.Sprintf("%v", oid)] = &OMFTreeNode{
-				Children: make(map[string]*OMFTreeNode),
-			}
-		}
+	visited := make(map[string]bool)
+
+	pending := slices.Clone(module_names)
 
-		return node_map[fmt.Sprintf("%v", oid)]
+	for _, module_name := range module_names {
+		visited[module_name] = true
 	}
 
-	oid_root := fmt.Sprintf("%v", oid[0])
+	for len(pending) > 0 {
+		module, err := gosmi.GetModule(pending[0])
+
+		pending = pending[1:]
+
+		if err != nil {
+			continue
+		}
+
+		for _, imp := range module.GetImports() {
+			if visited[imp.Module] {
+				continue
+			}
+
+			visited[imp.Module] = true
 
-	_, oid_root_exists := node_map[oid_root]
+			err := append_module(imp.Module)
+
+			if err != nil {
+				unresolved = append(unresolved, OMFUnresolvedImport{
+					Module:         module.Name,
+					ImportedModule: imp.Module,
+					Error:          err.Error(),
+				})
+
+				continue
+			}
 
-	if !oid_root_exists {
-		node_map[oid_root] = &OMFTreeNode{
-			Children: make(map[string]*OMFTreeNode),
+			pending = append(pending, imp.Module)
 		}
 	}
 
-	oid_rest := oid[1:]
+	return unresolved
+}
+
+func unresolved_imports_error(unresolved []OMFUnresolvedImport) error {
+	if len(unresolved) == 0 {
+		return nil
+	}
+
+	var errs []error
+
+	for _, imp := range unresolved {
+		errs = append(errs, fmt.Errorf("%w: %s imports %s: %s", ErrUnresolvedImport, imp.Module, imp.ImportedModule, imp.Error))
+	}
 
-	return get_leaf_by_oid(oid_rest, node_map[oid_root].Children)
+	return errors.Join(errs...)
 }
 
-func create_tree_from_node_list(nd_list []gosmi.SmiNode) map[string]*OMFTreeNode {
-	tree_map := make(map[string]*OMFTreeNode)
+func oid_arc_key(arc gosmi_types.SmiSubId) string {
+	return fmt.Sprintf("%d", arc)
+}
 
-	for _, node := range nd_list {
+func place_nodes_in_tree(nd_list []gosmi.SmiNode, tree *OMFCompleteModuleTree) error {
+	if tree.Tree == nil {
+		tree.Tree = make(OMFTreeChildren)
+	}
 
-		clean_node := get_leaf_by_oid(node.Oid, tree_map)
+	if tree.ConflictPolicy == "" {
+		tree.ConflictPolicy = OMFConflictKeepFirst
+	}
 
-		clean_node.Node = omfy_node(&node)
+	placer := new_tree_placer(tree.Tree, tree.ConflictPolicy, &tree.Conflicts)
 
-		clean_node.NodeOid = node.Oid.String()
+	for _, node := range nd_list {
+		err := placer.place(omfy_node(&node))
 
+		if err != nil {
+			return err
+		}
 	}
 
-	return tree_map
+	return nil
 }
 
-func create_complete_tree_from_module(mod *gosmi.SmiModule) (OMFCompleteModuleTree, error) {
+func create_complete_tree_from_module(mod *gosmi.SmiModule, options OMFCompleteTreeOptions) (OMFCompleteModuleTree, error) {
 	provided_module_tree, tree_err := create_omf_tree(mod)
 
 	if tree_err != nil {
@@ -76,30 +138,24 @@ func create_complete_tree_from_module(mod *gosmi.SmiModule) (OMFCompleteModuleTr
 	}
 
 	omf_complete_module_tree := OMFCompleteModuleTree{
-		Hash:         provided_module_tree.ModuleHash,
-		Contact:      provided_module_tree.ContactInfo,
-		Description:  provided_module_tree.Description,
-		Language:     provided_module_tree.Language,
-		Name:         provided_module_tree.Name,
-		Organization: provided_module_tree.Organization,
-		Path:         provided_module_tree.Path,
-		Reference:    provided_module_tree.Reference,
+		Hash:           provided_module_tree.ModuleHash,
+		Contact:        provided_module_tree.ContactInfo,
+		Description:    provided_module_tree.Description,
+		Language:       provided_module_tree.Language,
+		Name:           provided_module_tree.Name,
+		Organization:   provided_module_tree.Organization,
+		Path:           provided_module_tree.Path,
+		Reference:      provided_module_tree.Reference,
+		ConflictPolicy: options.ConflictPolicy,
 	}
 
-	imported_module_trees := make(map[string]*OMFCompleteModuleTree)
+	omf_complete_module_tree.UnresolvedImports = load_module_imports([]string{mod.Name})
 
-	for _, imported_module := range *provided_module_tree.ModuleImports {
+	if options.FailOnUnresolvedImports {
+		err := unresolved_imports_error(omf_complete_module_tree.UnresolvedImports)
 
-		_, visited := imported_module_trees[imported_module.ModName]
-
-		if visited {
-			continue
-		}
-
-		imported_module_err := append_module(imported_module.ModName)
-
-		if imported_module_err != nil {
-			return omf_complete_module_tree, nil
+		if err != nil {
+			return omf_complete_module_tree, err
 		}
 	}
 
@@ -109,15 +165,25 @@ func create_complete_tree_from_module(mod *gosmi.SmiModule) (OMFCompleteModuleTr
 		complete_node_collectoin = append(complete_node_collectoin, module.GetNodes()...)
 	}
 
-	complete_tree := create_tree_from_node_list(complete_node_collectoin)
-
-	omf_complete_module_tree.Tree = complete_tree
+	err := place_nodes_in_tree(complete_node_collectoin, &omf_complete_module_tree)
 
-	return omf_complete_module_tree, nil
+	return omf_complete_module_tree, err
 }
 
 func CreateCompleteTreeFromModule(path string, module_name string) (OMFCompleteModuleTree, error) {
-	init_gosmi(path, module_name)
+	return CreateCompleteTreeFromModuleWithOptions(path, module_name, OMFCompleteTreeOptions{})
+}
+
+func CreateCompleteTreeFromModuleWithPolicy(path string, module_name string, policy OMFConflictPolicy) (OMFCompleteModuleTree, error) {
+	return CreateCompleteTreeFromModuleWithOptions(path, module_name, OMFCompleteTreeOptions{ConflictPolicy: policy})
+}
+
+func CreateCompleteTreeFromModuleWithOptions(path string, module_name string, options OMFCompleteTreeOptions) (OMFCompleteModuleTree, error) {
+	err := init_gosmi(path, module_name)
+
+	if err != nil {
+		return OMFCompleteModuleTree{}, err
+	}
 
 	m, err := gosmi.GetModule(module_name)
 
@@ -125,29 +191,23 @@ func CreateCompleteTreeFromModule(path string, module_name string) (OMFCompleteM
 		return OMFCompleteModuleTree{}, err
 	}
 
-	return create_complete_tree_from_module(&m)
+	return create_complete_tree_from_module(&m, options)
 }
 
 func AddModuleInTree(module_name string, tree OMFCompleteModuleTree) (OMFCompleteModuleTree, error) {
 	_, err := gosmi.LoadModule(module_name)
 
 	if err != nil {
-		return OMFCompleteModuleTree{}, nil
+		return tree, err
 	}
 
 	new_module, err := gosmi.GetModule(module_name)
 
 	if err != nil {
-		return OMFCompleteModuleTree{}, nil
+		return tree, err
 	}
 
-	for _, new_node := range new_module.GetNodes() {
-		clean_node := get_leaf_by_oid(new_node.Oid, tree.Tree)
-
-		clean_node.Node = omfy_node(&new_node)
-
-		clean_node.NodeOid = new_node.Oid.String()
-	}
+	err = place_nodes_in_tree(new_module.GetNodes(), &tree)
 
-	return tree, nil
+	return tree, err
 }
@@ -0,0 +1,192 @@
+package omifier
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+const omf_testdata_path = "testdata"
+
+func TestCompleteTreeFindsRealOids(t *testing.T) {
+	tree, err := CreateCompleteTreeFromModule(omf_testdata_path, "ACME-TEST-MIB")
+
+	if err != nil {
+		t.Fatalf("CreateCompleteTreeFromModule: %s", err)
+	}
+
+	tests := []struct {
+		oid  string
+		name string
+	}{
+		{"1", "iso"},
+		{"1.3", "org"},
+		{"1.3.6.1.4.1", "enterprises"},
+		{"1.3.6.1.4.1.99999", "acmeTestMIB"},
+		{"1.3.6.1.4.1.99999.1", "acmeObjects"},
+		{"1.3.6.1.4.1.99999.1.2", "acmeUptime"},
+		{"1.3.6.1.4.1.99999.1.10", "acmePortTable"},
+		{"1.3.6.1.4.1.99999.1.10.1", "acmePortEntry"},
+		{"1.3.6.1.4.1.99999.1.10.1.2", "acmePortName"},
+		{"1.3.6.1.4.1.99999.1.10.1.6", "acmePortRowStatus"},
+		{"1.3.6.1.4.1.99999.3.2.1", "acmeCompliance"},
+	}
+
+	for _, tt := range tests {
+		node := tree.Find(tt.oid)
+
+		if node == nil {
+			t.Errorf("Find(%s) = nil, want %s", tt.oid, tt.name)
+
+			continue
+		}
+
+		if node.Node.Name != tt.name || node.NodeOid != tt.oid {
+			t.Errorf("Find(%s) = %s (%s), want %s", tt.oid, node.Node.Name, node.NodeOid, tt.name)
+		}
+	}
+
+	if tree.Find("1.3.6.1.4.1.99999.1.99") != nil {
+		t.Errorf("Find returned a node for an OID no module defines")
+	}
+}
+
+func TestCompleteTreeArcKeysMatchNodeOids(t *testing.T) {
+	tree, err := CreateCompleteTreeFromModule(omf_testdata_path, "ACME-TEST-MIB")
+
+	if err != nil {
+		t.Fatalf("CreateCompleteTreeFromModule: %s", err)
+	}
+
+	visited := 0
+
+	err = tree.Walk(OMFTreeVisitor{Pre: func(node *OMFTreeNode, depth int) error {
+		visited++
+
+		arcs := split_oid_arcs(node.NodeOid)
+
+		if len(arcs) != depth+1 {
+			t.Errorf("%s (%s) found at depth %d", node.Node.Name, node.NodeOid, depth)
+		}
+
+		for arc, child := range node.Children {
+			child_arcs := split_oid_arcs(child.NodeOid)
+
+			if child_arcs[len(child_arcs)-1] != arc || child.NodeOid != node.NodeOid+"."+arc {
+				t.Errorf("child %s (%s) of %s is keyed %q", child.Node.Name, child.NodeOid, node.NodeOid, arc)
+			}
+		}
+
+		return nil
+	}})
+
+	if err != nil {
+		t.Fatalf("Walk: %s", err)
+	}
+
+	if visited == 0 {
+		t.Fatalf("Walk visited no nodes")
+	}
+}
+
+func TestCompleteTreeSynthesizesIntermediateNodes(t *testing.T) {
+	tree, err := CreateCompleteTreeFromModule(omf_testdata_path, "GAP-MIB")
+
+	if err != nil {
+		t.Fatalf("CreateCompleteTreeFromModule: %s", err)
+	}
+
+	tests := []struct {
+		oid         string
+		name        string
+		synthesized bool
+	}{
+		{"1", "iso", false},
+		{"1.3", "org", true},
+		{"1.3.6.1", "internet", true},
+		{"1.3.6.1.4.1", "enterprises", true},
+		{"1.3.6.1.4.1.77778", "gapRoot", false},
+		{"1.3.6.1.4.1.77778.7", "1.3.6.1.4.1.77778.7", true},
+		{"1.3.6.1.4.1.77778.7.3", "gapLeaf", false},
+	}
+
+	for _, tt := range tests {
+		node := tree.Find(tt.oid)
+
+		if node == nil {
+			t.Errorf("Find(%s) = nil", tt.oid)
+
+			continue
+		}
+
+		if node.Node.Name != tt.name || node.Synthesized != tt.synthesized {
+			t.Errorf("Find(%s) = %s synthesized %v, want %s synthesized %v", tt.oid, node.Node.Name, node.Synthesized, tt.name, tt.synthesized)
+		}
+
+		if node.NodeOid != tt.oid || node.Node.Oid != tt.oid {
+			t.Errorf("Find(%s) has NodeOid %q and Oid %q", tt.oid, node.NodeOid, node.Node.Oid)
+		}
+	}
+}
+
+func TestCompleteTreeFollowsImportsThroughCycles(t *testing.T) {
+	tree, err := CreateCompleteTreeFromModuleWithOptions(omf_testdata_path, "CYCLE-B-MIB", OMFCompleteTreeOptions{FailOnUnresolvedImports: true})
+
+	if err != nil {
+		t.Fatalf("CreateCompleteTreeFromModuleWithOptions: %s", err)
+	}
+
+	if len(tree.UnresolvedImports) != 0 {
+		t.Errorf("UnresolvedImports = %+v", tree.UnresolvedImports)
+	}
+
+	tests := []struct {
+		oid    string
+		name   string
+		module string
+	}{
+		{"1.3.6.1.4.1", "enterprises", "SNMPv2-SMI"},
+		{"1.3.6.1.4.1.77783", "cycleA", "CYCLE-A-MIB"},
+		{"1.3.6.1.4.1.77783.2", "cycleB", "CYCLE-B-MIB"},
+	}
+
+	for _, tt := range tests {
+		node := tree.Find(tt.oid)
+
+		if node == nil || node.Node.Name != tt.name || node.Node.Module != tt.module {
+			t.Errorf("Find(%s) = %+v, want %s::%s", tt.oid, node, tt.module, tt.name)
+		}
+	}
+}
+
+func TestCompleteTreeUnresolvedImports(t *testing.T) {
+	tree, err := CreateCompleteTreeFromModule(omf_testdata_path, "ACME-V1-MIB")
+
+	if err != nil {
+		t.Fatalf("CreateCompleteTreeFromModule: %s", err)
+	}
+
+	var imported []string
+
+	for _, imp := range tree.UnresolvedImports {
+		if imp.Module != "ACME-V1-MIB" || imp.Error == "" {
+			t.Errorf("unresolved import reported as %+v", imp)
+		}
+
+		imported = append(imported, imp.ImportedModule)
+	}
+
+	if !slices.Equal(imported, []string{"RFC-1212", "RFC-1215"}) {
+		t.Errorf("UnresolvedImports name %v, want RFC-1212 and RFC-1215", imported)
+	}
+
+	if tree.Find("1.3.6.1.4.1.99998.1.4") == nil {
+		t.Errorf("the tree was not built past the unresolved imports")
+	}
+
+	_, err = CreateCompleteTreeFromModuleWithOptions(omf_testdata_path, "ACME-V1-MIB", OMFCompleteTreeOptions{FailOnUnresolvedImports: true})
+
+	if !errors.Is(err, ErrUnresolvedImport) {
+		t.Errorf("FailOnUnresolvedImports returned %v, want ErrUnresolvedImport", err)
+	}
+}
@@ -0,0 +1,350 @@
+package omifier
+
+import (
+	"errors"
+	"fmt"
+	"go/format"
+	"io"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var go_identifier_regex = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+var go_base_types = map[string]string{
+	"Integer32":        "int32",
+	"Integer64":        "int64",
+	"Unsigned32":       "uint32",
+	"Unsigned64":       "uint64",
+	"Counter32":        "uint32",
+	"Counter64":        "uint64",
+	"Gauge32":          "uint32",
+	"TimeTicks":        "uint32",
+	"IpAddress":        "net.IP",
+	"MacAddress":       "net.HardwareAddr",
+	"PhysAddress":      "net.HardwareAddr",
+	"ObjectIdentifier": "string",
+	"Bits":             "[]byte",
+	"OctetString":      "[]byte",
+}
+
+type go_writer struct {
+	module     *OMFModule
+	types      strings.Builder
+	body       strings.Builder
+	imports    map[string]bool
+	type_names map[string]bool
+	oid_names  map[string]string
+}
+
+func go_identifier(name string) string {
+	parts := go_identifier_regex.Split(name, -1)
+
+	var identifier strings.Builder
+
+	for _, part := range parts {
+		if part == "" {
+			continue
+		}
+
+		runes := []rune(part)
+
+		runes[0] = unicode.ToUpper(runes[0])
+
+		identifier.WriteString(string(runes))
+	}
+
+	if identifier.Len() == 0 || unicode.IsDigit([]rune(identifier.String())[0]) {
+		return "X" + identifier.String()
+	}
+
+	return identifier.String()
+}
+
+func go_package_name(module_name string) string {
+	name := strings.ToLower(go_identifier_regex.ReplaceAllString(module_name, ""))
+
+	if name == "" || unicode.IsDigit([]rune(name)[0]) {
+		return "mib" + name
+	}
+
+	return name
+}
+
+func new_go_writer(mod *OMFModule) *go_writer {
+	return &go_writer{
+		module:     mod,
+		imports:    make(map[string]bool),
+		type_names: make(map[string]bool),
+		oid_names:  omf_module_oid_names(mod),
+	}
+}
+
+func (w *go_writer) printf(format string, args ...any) {
+	fmt.Fprintf(&w.body, format, args...)
+}
+
+func (w *go_writer) typef(format string, args ...any) {
+	fmt.Fprintf(&w.types, format, args...)
+}
+
+func (w *go_writer) write_enum(type_name string, source_name string, tp *OMFType, description string) {
+	w.typef("// %s enumerates the values of %s.\n", type_name, source_name)
+
+	if description != "" {
+		w.typef("//\n// %s\n", normalize_smi_text(description))
+	}
+
+	w.typef("type %s int64\n\nconst (\n", type_name)
+
+	labels := smi_ordered_enum(tp.Enum)
+
+	for _, label := range labels {
+		w.typef("\t%s%s %s = %d\n", type_name, go_identifier(label), type_name, tp.Enum[label])
+	}
+
+	w.typef(")\n\nfunc (v %s) String() string {\n\tswitch v {\n", type_name)
+
+	for _, label := range labels {
+		w.typef("\tcase %s%s:\n\t\treturn %q\n", type_name, go_identifier(label), label)
+	}
+
+	w.typef("\t}\n\n\treturn fmt.Sprintf(\"%%d\", int64(v))\n}\n\n")
+
+	w.imports["fmt"] = true
+}
+
+func (w *go_writer) base_type(tp *OMFType) string {
+	if tp.BaseType == "OctetString" && yang_format_string_regex.MatchString(tp.Format) {
+		return "string"
+	}
+
+	if tp.Name == "DisplayString" || tp.Name == "SnmpAdminString" {
+		return "string"
+	}
+
+	go_type, ok := go_base_types[tp.Name]
+
+	if !ok {
+		go_type, ok = go_base_types[tp.BaseType]
+	}
+
+	if !ok {
+		return "[]byte"
+	}
+
+	if strings.HasPrefix(go_type, "net.") {
+		w.imports["net"] = true
+	}
+
+	return go_type
+}
+
+func (w *go_writer) field_type(nd *OMFNode) string {
+	tp := nd.Type
+
+	if tp == nil {
+		return "[]byte"
+	}
+
+	named := tp.Decl != "ImplicitType" && tp.Name != "" && smi_identifier_regex.MatchString(tp.Name)
+
+	if tp.BaseType == "Enum" && len(tp.Enum) > 0 {
+		type_name := go_identifier(nd.Name)
+
+		if named {
+			type_name = go_identifier(tp.Name)
+		}
+
+		if !w.type_names[type_name] {
+			w.type_names[type_name] = true
+
+			if named {
+				w.write_enum(type_name, tp.Name, tp, tp.Description)
+			} else {
+				w.write_enum(type_name, nd.Name, tp, "")
+			}
+		}
+
+		return type_name
+	}
+
+	if named && w.type_names[go_identifier(tp.Name)] {
+		return go_identifier(tp.Name)
+	}
+
+	return w.base_type(tp)
+}
+
+func (w *go_writer) write_module_types() {
+	for _, tp := range w.module.Types {
+		if !smi_identifier_regex.MatchString(tp.Name) {
+			continue
+		}
+
+		type_name := go_identifier(tp.Name)
+
+		if w.type_names[type_name] {
+			continue
+		}
+
+		w.type_names[type_name] = true
+
+		if tp.BaseType == "Enum" && len(tp.Enum) > 0 {
+			w.write_enum(type_name, tp.Name, &tp, tp.Description)
+
+			continue
+		}
+
+		w.typef("// %s is the %s textual convention.\n", type_name, tp.Name)
+
+		if tp.Description != "" {
+			w.typef("//\n// %s\n", normalize_smi_text(tp.Description))
+		}
+
+		w.typef("type %s %s\n\n", type_name, w.base_type(&OMFType{BaseType: tp.BaseType, Format: tp.Format}))
+	}
+}
+
+func (w *go_writer) field(nd *OMFNode, instance string) string {
+	return fmt.Sprintf("\t%s %s `oid:\"%s%s\"`\n", go_identifier(nd.Name), w.field_type(nd), nd.Oid, instance)
+}
+
+func (w *go_writer) write_scalars() {
+	var groups []string
+
+	group_fields := make(map[string]string)
+
+	group_oids := make(map[string]string)
+
+	for _, sc := range w.module.Scalars {
+		group, group_oid := omf_scalar_parent(w.oid_names, &sc.OMFNode)
+
+		if _, ok := group_fields[group]; !ok {
+			groups = append(groups, group)
+
+			group_oids[group] = group_oid
+		}
+
+		group_fields[group] += w.field(&sc.OMFNode, ".0")
+	}
+
+	for _, group := range groups {
+		w.printf("// %s holds the scalars registered under %s (%s).\n", go_identifier(group), group, group_oids[group])
+
+		w.printf("type %s struct {\n%s}\n\n", go_identifier(group), group_fields[group])
+	}
+}
+
+func (w *go_writer) write_table(tb *OMFTable) {
+	if !smi_identifier_regex.MatchString(tb.Entry.Name) {
+		return
+	}
+
+	table_name := go_identifier(tb.Name)
+
+	entry_name := go_identifier(tb.Entry.Name)
+
+	index_name := entry_name + "Index"
+
+	var index_fields, row_fields string
+
+	indexes := make(map[string]bool)
+
+	for _, idx := range tb.Indexes {
+		indexes[idx.Name] = true
+
+		index_fields += w.field(&idx.OMFNode, "")
+	}
+
+	for _, col := range tb.Columns {
+		if indexes[col.Name] && col.Access == "NotAccessible" {
+			continue
+		}
+
+		row_fields += w.field(&col, "")
+	}
+
+	w.printf("// %sOid is the OID of %s.\nconst %sOid = %q\n\n", table_name, tb.Name, table_name, tb.Oid)
+
+	w.printf("// %s holds the INDEX of %s.\n", index_name, tb.Entry.Name)
+
+	w.printf("type %s struct {\n%s}\n\n", index_name, index_fields)
+
+	w.printf("// %s is a row of %s (%s).\n", entry_name, tb.Name, tb.Entry.Oid)
+
+	if tb.Entry.Description != "" {
+		w.printf("//\n// %s\n", normalize_smi_text(tb.Entry.Description))
+	}
+
+	w.printf("type %s struct {\n\tIndex %s\n%s}\n\n", entry_name, index_name, row_fields)
+}
+
+func render_go_source(mod *OMFModule, package_name string) string {
+	w := new_go_writer(mod)
+
+	w.write_module_types()
+
+	w.write_scalars()
+
+	for _, tb := range mod.Tables {
+		w.write_table(&tb)
+	}
+
+	var out strings.Builder
+
+	fmt.Fprintf(&out, "// Code generated by omifier from %s. DO NOT EDIT.\n\n", mod.Name)
+
+	fmt.Fprintf(&out, "package %s\n\n", package_name)
+
+	if len(w.imports) > 0 {
+		out.WriteString("import (\n")
+
+		for _, imp := range []string{"fmt", "net"} {
+			if w.imports[imp] {
+				fmt.Fprintf(&out, "\t%q\n", imp)
+			}
+		}
+
+		out.WriteString(")\n\n")
+	}
+
+	out.WriteString(w.types.String())
+
+	out.WriteString(w.body.String())
+
+	return out.String()
+}
+
+func GenerateGoSource(mod OMFModule, package_name string) (string, error) {
+	if mod.Name == "" {
+		return "", errors.New("cannot generate go source for an unnamed module")
+	}
+
+	if package_name == "" {
+		package_name = go_package_name(mod.Name)
+	}
+
+	source := render_go_source(&mod, package_name)
+
+	formatted, err := format.Source([]byte(source))
+
+	if err != nil {
+		return source, err
+	}
+
+	return string(formatted), nil
+}
+
+func WriteGoSource(out io.Writer, mod OMFModule, package_name string) error {
+	source, err := GenerateGoSource(mod, package_name)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(out, source)
+
+	return err
+}
@@ -0,0 +1,171 @@
+package omifier
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+type omf_go_declarations struct {
+	fields map[string]string
+	consts map[string]string
+}
+
+func omf_parse_go_source(t *testing.T, source string) omf_go_declarations {
+	t.Helper()
+
+	fset := token.NewFileSet()
+
+	file, err := parser.ParseFile(fset, "acme.go", source, 0)
+
+	if err != nil {
+		t.Fatalf("generated source does not parse: %s\n%s", err, source)
+	}
+
+	decls := omf_go_declarations{
+		fields: make(map[string]string),
+		consts: make(map[string]string),
+	}
+
+	expr_string := func(expr ast.Expr) string {
+		var out strings.Builder
+
+		printer.Fprint(&out, fset, expr)
+
+		return out.String()
+	}
+
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+
+		if !ok {
+			continue
+		}
+
+		for _, spec := range gen.Specs {
+			switch spec := spec.(type) {
+			case *ast.TypeSpec:
+				st, ok := spec.Type.(*ast.StructType)
+
+				if !ok {
+					decls.fields[spec.Name.Name] = expr_string(spec.Type)
+
+					continue
+				}
+
+				for _, field := range st.Fields.List {
+					tag := ""
+
+					if field.Tag != nil {
+						tag = " " + field.Tag.Value
+					}
+
+					for _, name := range field.Names {
+						decls.fields[spec.Name.Name+"."+name.Name] = expr_string(field.Type) + tag
+					}
+				}
+			case *ast.ValueSpec:
+				for i, name := range spec.Names {
+					decls.consts[name.Name] = expr_string(spec.Type) + " = " + expr_string(spec.Values[i])
+				}
+			}
+		}
+	}
+
+	return decls
+}
+
+func TestGenerateGoSource(t *testing.T) {
+	mod, err := GetOmfCommomStruct(omf_testdata_path, "ACME-TEST-MIB", false)
+
+	if err != nil {
+		t.Fatalf("GetOmfCommomStruct: %s", err)
+	}
+
+	source, err := GenerateGoSource(mod, "")
+
+	if err != nil {
+		t.Fatalf("GenerateGoSource: %s", err)
+	}
+
+	if !strings.Contains(source, "\npackage acmetestmib\n") {
+		t.Errorf("generated source is not in package acmetestmib:\n%s", source)
+	}
+
+	decls := omf_parse_go_source(t, source)
+
+	fields := []struct {
+		name string
+		want string
+	}{
+		{"AcmeLegacyId", "int32"},
+		{"AcmePortState", "int64"},
+		{"AcmeObjects.AcmeSystemName", "string `oid:\"1.3.6.1.4.1.99999.1.1.0\"`"},
+		{"AcmeObjects.AcmeUptime", "uint32 `oid:\"1.3.6.1.4.1.99999.1.2.0\"`"},
+		{"AcmeObjects.AcmeLegacyCounter", "AcmeLegacyId `oid:\"1.3.6.1.4.1.99999.1.3.0\"`"},
+		{"AcmePortEntryIndex.AcmePortIndex", "int32 `oid:\"1.3.6.1.4.1.99999.1.10.1.1\"`"},
+		{"AcmePortEntry.Index", "AcmePortEntryIndex"},
+		{"AcmePortEntry.AcmePortName", "string `oid:\"1.3.6.1.4.1.99999.1.10.1.2\"`"},
+		{"AcmePortEntry.AcmePortMac", "net.HardwareAddr `oid:\"1.3.6.1.4.1.99999.1.10.1.3\"`"},
+		{"AcmePortEntry.AcmePortState", "AcmePortState `oid:\"1.3.6.1.4.1.99999.1.10.1.4\"`"},
+		{"AcmePortEntry.AcmePortInOctets", "uint32 `oid:\"1.3.6.1.4.1.99999.1.10.1.5\"`"},
+		{"AcmePortEntry.AcmePortRowStatus", "RowStatus `oid:\"1.3.6.1.4.1.99999.1.10.1.6\"`"},
+	}
+
+	for _, tt := range fields {
+		if got, ok := decls.fields[tt.name]; !ok || got != tt.want {
+			t.Errorf("%s is %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	if _, ok := decls.fields["AcmePortEntry.AcmePortIndex"]; ok {
+		t.Errorf("the not-accessible index acmePortIndex is repeated as a row field")
+	}
+
+	consts := []struct {
+		name string
+		want string
+	}{
+		{"AcmePortStateDown", "AcmePortState = 0"},
+		{"AcmePortStateUp", "AcmePortState = 1"},
+		{"AcmePortStateTesting", "AcmePortState = 2"},
+		{"RowStatusActive", "RowStatus = 1"},
+		{"RowStatusDestroy", "RowStatus = 6"},
+		{"AcmePortTableOid", ` = "1.3.6.1.4.1.99999.1.10"`},
+	}
+
+	for _, tt := range consts {
+		if got, ok := decls.consts[tt.name]; !ok || got != tt.want {
+			t.Errorf("const %s is %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	if !strings.Contains(source, "\tcase AcmePortStateTesting:\n\t\treturn \"testing\"\n") {
+		t.Errorf("AcmePortState.String does not name the testing label:\n%s", source)
+	}
+}
+
+func TestGoIdentifier(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"acmePortEntry", "AcmePortEntry"},
+		{"not-in-service", "NotInService"},
+		{"802dot1", "X802dot1"},
+		{"", "X"},
+	}
+
+	for _, tt := range tests {
+		if got := go_identifier(tt.name); got != tt.want {
+			t.Errorf("go_identifier(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	if got := go_package_name("ACME-TEST-MIB"); got != "acmetestmib" {
+		t.Errorf("go_package_name(ACME-TEST-MIB) = %q", got)
+	}
+}
@@ -0,0 +1,447 @@
+package omifier
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+type OMFImportRef struct {
+	Module         string
+	ImportedModule string
+	Name           string
+}
+
+type OMFImportEdge struct {
+	Module         string
+	ImportedModule string
+	Names          []string
+}
+
+type OMFImportGraph struct {
+	Modules         []string
+	ExternalModules []string
+	Edges           []OMFImportEdge
+	Unused          []OMFImportRef
+	Missing         []OMFImportRef
+}
+
+var import_graph_macro_regex = regexp.MustCompile(`^[A-Z][A-Z0-9]*(-[A-Z0-9]+)+$`)
+
+var import_graph_base_types = map[string]string{
+	"Integer32":  "Integer32",
+	"Unsigned32": "Unsigned32",
+	"Counter32":  "Unsigned32",
+	"Gauge32":    "Unsigned32",
+	"TimeTicks":  "Unsigned32",
+	"Counter64":  "Unsigned64",
+	"IpAddress":  "OctetString",
+	"Opaque":     "OctetString",
+}
+
+// ASN.1 types SNMPv2-SMI only uses inside its own macro definitions; libsmi
+// does not expose them, so they are neither checked nor reported unused.
+var import_graph_syntax_names = map[string]bool{
+	"ObjectName":        true,
+	"ObjectSyntax":      true,
+	"NotificationName":  true,
+	"SimpleSyntax":      true,
+	"ApplicationSyntax": true,
+	"ExtUTCTime":        true,
+}
+
+func import_graph_macro_used(mod *OMFModule, macro string) bool {
+	switch macro {
+	case "MODULE-IDENTITY":
+		return mod.IdentityName != ""
+	case "OBJECT-TYPE":
+		return len(mod.Scalars) > 0 || len(mod.Tables) > 0
+	case "NOTIFICATION-TYPE", "TRAP-TYPE":
+		return len(mod.Notifications) > 0
+	case "MODULE-COMPLIANCE":
+		return len(mod.Compliances) > 0
+	case "TEXTUAL-CONVENTION":
+		return slices.ContainsFunc(mod.Types, func(tp OMFType) bool { return tp.Decl == "TextualConvention" })
+	case "OBJECT-IDENTITY":
+		return slices.ContainsFunc(mod.OtherNodes, func(nd OMFNode) bool { return nd.Decl == "ObjectIdentity" })
+	case "OBJECT-GROUP":
+		return slices.ContainsFunc(mod.Groups, func(gr OMFGroup) bool { return gr.Decl != "NotificationGroup" })
+	case "NOTIFICATION-GROUP":
+		return slices.ContainsFunc(mod.Groups, func(gr OMFGroup) bool { return gr.Decl == "NotificationGroup" })
+	}
+
+	return true
+}
+
+func import_graph_defined_names(mod *OMFModule) map[string]string {
+	defined := make(map[string]string)
+
+	for _, nd := range omf_module_nodes(mod) {
+		defined[nd.Name] = nd.Oid
+	}
+
+	if mod.IdentityName != "" {
+		defined[mod.IdentityName] = mod.IdentityOid
+	}
+
+	for _, tp := range mod.Types {
+		defined[tp.Name] = ""
+	}
+
+	return defined
+}
+
+// Names a module refers to: types, objects listed by indexes, notifications,
+// groups and compliances, and the nearest named ancestor of every OID.
+func import_graph_referenced_names(mod *OMFModule, import_oids map[string]string) map[string]bool {
+	referenced := make(map[string]bool)
+
+	reference_type := func(tp *OMFType) {
+		if tp != nil {
+			referenced[tp.Name] = true
+		}
+	}
+
+	oid_names := omf_module_oid_names(mod)
+
+	if mod.IdentityName != "" && mod.IdentityOid != "" {
+		oid_names[mod.IdentityOid] = mod.IdentityName
+	}
+
+	reference_parent := func(oid string) {
+		arcs := split_oid_arcs(oid)
+
+		for prefix_len := len(arcs) - 1; prefix_len > 0; prefix_len-- {
+			prefix := strings.Join(arcs[:prefix_len], ".")
+
+			if _, ok := oid_names[prefix]; ok {
+				return
+			}
+
+			if name, ok := import_oids[prefix]; ok {
+				referenced[name] = true
+
+				return
+			}
+		}
+	}
+
+	for _, nd := range omf_module_nodes(mod) {
+		reference_type(nd.Type)
+
+		reference_parent(nd.Oid)
+	}
+
+	reference_parent(mod.IdentityOid)
+
+	for _, tp := range mod.Types {
+		referenced[tp.BaseType] = true
+	}
+
+	for _, tb := range mod.Tables {
+		for _, idx := range tb.Indexes {
+			referenced[idx.Name] = true
+		}
+	}
+
+	for _, nf := range mod.Notifications {
+		for _, obj := range nf.Objects {
+			referenced[obj.Name] = true
+		}
+	}
+
+	for _, gr := range mod.Groups {
+		for _, member := range gr.Members {
+			referenced[member.Name] = true
+		}
+	}
+
+	for _, cp := range mod.Compliances {
+		for _, cp_mod := range cp.Modules {
+			for _, group := range cp_mod.MandatoryGroups {
+				referenced[group] = true
+			}
+
+			for _, item := range append(slices.Clone(cp_mod.Groups), cp_mod.Objects...) {
+				referenced[item.Name] = true
+			}
+		}
+	}
+
+	delete(referenced, "")
+
+	return referenced
+}
+
+func import_oids_of(mod *OMFModule, defined map[string]map[string]string) map[string]string {
+	import_oids := make(map[string]string)
+
+	for _, imp := range mod.Imports {
+		for _, name := range imp.ImportedNodes {
+			if oid := defined[imp.ModName][name]; oid != "" {
+				import_oids[oid] = name
+
+				continue
+			}
+
+			for oid, well_known := range omf_well_known_nodes {
+				if well_known.Name == name && (well_known.Module == imp.ModName || smi_v1_import_modules[imp.ModName] == well_known.Module) {
+					import_oids[oid] = name
+				}
+			}
+		}
+	}
+
+	return import_oids
+}
+
+func create_import_graph(mods []OMFModule) OMFImportGraph {
+	var graph OMFImportGraph
+
+	defined := make(map[string]map[string]string)
+
+	for _, mod := range mods {
+		defined[mod.Name] = import_graph_defined_names(&mod)
+
+		graph.Modules = append(graph.Modules, mod.Name)
+	}
+
+	sort.Strings(graph.Modules)
+
+	external := make(map[string]bool)
+
+	for _, mod := range mods {
+		referenced := import_graph_referenced_names(&mod, import_oids_of(&mod, defined))
+
+		imported := make(map[string]bool)
+
+		for _, imp := range mod.Imports {
+			names := slices.Clone(imp.ImportedNodes)
+
+			sort.Strings(names)
+
+			graph.Edges = append(graph.Edges, OMFImportEdge{Module: mod.Name, ImportedModule: imp.ModName, Names: names})
+
+			imported_defined, in_set := defined[imp.ModName]
+
+			if !in_set {
+				external[imp.ModName] = true
+			}
+
+			for _, name := range names {
+				imported[name] = true
+
+				ref := OMFImportRef{Module: mod.Name, ImportedModule: imp.ModName, Name: name}
+
+				if import_graph_macro_regex.MatchString(name) {
+					if !import_graph_macro_used(&mod, name) {
+						graph.Unused = append(graph.Unused, ref)
+					}
+
+					continue
+				}
+
+				if import_graph_syntax_names[name] {
+					continue
+				}
+
+				base_type, is_base_type := import_graph_base_types[smi_v1_import_names[name]]
+
+				if !is_base_type {
+					base_type, is_base_type = import_graph_base_types[name]
+				}
+
+				used := referenced[name] || referenced[smi_v1_import_names[name]]
+
+				if !used && is_base_type {
+					used = slices.ContainsFunc(mod.Types, func(tp OMFType) bool { return tp.BaseType == base_type })
+				}
+
+				if !used {
+					graph.Unused = append(graph.Unused, ref)
+				}
+
+				if _, ok := imported_defined[name]; in_set && !ok && !is_base_type {
+					graph.Missing = append(graph.Missing, ref)
+				}
+			}
+		}
+
+		var not_imported []string
+
+		for name := range referenced {
+			if _, is_base_type := import_graph_base_types[name]; is_base_type || imported[name] {
+				continue
+			}
+
+			if _, local := defined[mod.Name][name]; local {
+				continue
+			}
+
+			not_imported = append(not_imported, name)
+		}
+
+		sort.Strings(not_imported)
+
+		for _, name := range not_imported {
+			for _, other := range graph.Modules {
+				if _, ok := defined[other][name]; ok && other != mod.Name {
+					graph.Missing = append(graph.Missing, OMFImportRef{Module: mod.Name, ImportedModule: other, Name: name})
+
+					break
+				}
+			}
+		}
+	}
+
+	for module := range external {
+		graph.ExternalModules = append(graph.ExternalModules, module)
+	}
+
+	sort.Strings(graph.ExternalModules)
+
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		if graph.Edges[i].Module == graph.Edges[j].Module {
+			return graph.Edges[i].ImportedModule < graph.Edges[j].ImportedModule
+		}
+
+		return graph.Edges[i].Module < graph.Edges[j].Module
+	})
+
+	return graph
+}
+
+func BuildOmfImportGraph(mods []OMFModule) OMFImportGraph {
+	return create_import_graph(mods)
+}
+
+func GetOmfImportGraph(path string, module_names ...string) (OMFImportGraph, error) {
+	var mods []OMFModule
+
+	for _, module_name := range module_names {
+		mod, err := GetOmfCommomStruct(path, module_name, false)
+
+		if err != nil {
+			return OMFImportGraph{}, err
+		}
+
+		mods = append(mods, mod)
+	}
+
+	return create_import_graph(mods), nil
+}
+
+func (g OMFImportGraph) Dependencies(module_name string) []string {
+	var modules []string
+
+	for _, edge := range g.Edges {
+		if edge.Module == module_name {
+			modules = append(modules, edge.ImportedModule)
+		}
+	}
+
+	return modules
+}
+
+func (g OMFImportGraph) Dependents(module_name string) []string {
+	var modules []string
+
+	for _, edge := range g.Edges {
+		if edge.ImportedModule == module_name && !slices.Contains(modules, edge.Module) {
+			modules = append(modules, edge.Module)
+		}
+	}
+
+	return modules
+}
+
+func (g OMFImportGraph) ImportersOf(module_name string, name string) []string {
+	var modules []string
+
+	for _, edge := range g.Edges {
+		if edge.ImportedModule == module_name && slices.Contains(edge.Names, name) {
+			modules = append(modules, edge.Module)
+		}
+	}
+
+	return modules
+}
+
+func (g OMFImportGraph) all_modules() []string {
+	modules := append(slices.Clone(g.Modules), g.ExternalModules...)
+
+	sort.Strings(modules)
+
+	return slices.Compact(modules)
+}
+
+func GenerateImportGraphDot(graph OMFImportGraph) string {
+	var out strings.Builder
+
+	out.WriteString("digraph imports {\n  rankdir=LR;\n  node [shape=box];\n\n")
+
+	for _, module := range graph.all_modules() {
+		if slices.Contains(graph.ExternalModules, module) {
+			fmt.Fprintf(&out, "  %q [style=dashed];\n", module)
+		} else {
+			fmt.Fprintf(&out, "  %q;\n", module)
+		}
+	}
+
+	if len(graph.Edges) > 0 {
+		out.WriteString("\n")
+	}
+
+	for _, edge := range graph.Edges {
+		fmt.Fprintf(&out, "  %q -> %q [tooltip=%q];\n", edge.Module, edge.ImportedModule, strings.Join(edge.Names, ", "))
+	}
+
+	out.WriteString("}\n")
+
+	return out.String()
+}
+
+func WriteImportGraphDot(out io.Writer, graph OMFImportGraph) error {
+	_, err := io.WriteString(out, GenerateImportGraphDot(graph))
+
+	return err
+}
+
+func GenerateImportGraphMermaid(graph OMFImportGraph) string {
+	var out strings.Builder
+
+	out.WriteString("graph LR\n")
+
+	ids := make(map[string]string)
+
+	for idx, module := range graph.all_modules() {
+		ids[module] = fmt.Sprintf("m%d", idx)
+
+		fmt.Fprintf(&out, "  %s[\"%s\"]", ids[module], module)
+
+		if slices.Contains(graph.ExternalModules, module) {
+			out.WriteString(":::external")
+		}
+
+		out.WriteString("\n")
+	}
+
+	for _, edge := range graph.Edges {
+		fmt.Fprintf(&out, "  %s --> %s\n", ids[edge.Module], ids[edge.ImportedModule])
+	}
+
+	if len(graph.ExternalModules) > 0 {
+		out.WriteString("  classDef external stroke-dasharray: 5 5\n")
+	}
+
+	return out.String()
+}
+
+func WriteImportGraphMermaid(out io.Writer, graph OMFImportGraph) error {
+	_, err := io.WriteString(out, GenerateImportGraphMermaid(graph))
+
+	return err
+}
@@ -0,0 +1,118 @@
+package omifier
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestImportGraphDependencies(t *testing.T) {
+	graph, err := GetOmfImportGraph(omf_testdata_path, "ACME-TEST-MIB", "ACME-V1-MIB", "CCITT-MIB", "SNMPv2-SMI", "SNMPv2-TC")
+
+	if err != nil {
+		t.Fatalf("GetOmfImportGraph: %s", err)
+	}
+
+	if want := []string{"RFC-1212", "RFC-1215", "SNMPv2-CONF"}; !slices.Equal(graph.ExternalModules, want) {
+		t.Errorf("ExternalModules = %v, want %v", graph.ExternalModules, want)
+	}
+
+	tests := []struct {
+		name string
+		got  []string
+		want []string
+	}{
+		{"Dependencies(ACME-TEST-MIB)", graph.Dependencies("ACME-TEST-MIB"), []string{"SNMPv2-CONF", "SNMPv2-SMI", "SNMPv2-TC"}},
+		{"Dependents(SNMPv2-TC)", graph.Dependents("SNMPv2-TC"), []string{"ACME-TEST-MIB", "ACME-V1-MIB"}},
+		{"ImportersOf(SNMPv2-TC, DisplayString)", graph.ImportersOf("SNMPv2-TC", "DisplayString"), []string{"ACME-TEST-MIB", "ACME-V1-MIB"}},
+		{"ImportersOf(SNMPv2-TC, RowStatus)", graph.ImportersOf("SNMPv2-TC", "RowStatus"), []string{"ACME-TEST-MIB"}},
+		{"ImportersOf(SNMPv2-SMI, enterprises)", graph.ImportersOf("SNMPv2-SMI", "enterprises"), []string{"ACME-TEST-MIB", "ACME-V1-MIB", "CCITT-MIB"}},
+	}
+
+	for _, tt := range tests {
+		if !slices.Equal(tt.got, tt.want) {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+
+	want_unused := []OMFImportRef{{Module: "ACME-V1-MIB", ImportedModule: "SNMPv2-SMI", Name: "TimeTicks"}}
+
+	if !slices.Equal(graph.Unused, want_unused) {
+		t.Errorf("Unused = %+v, want %+v", graph.Unused, want_unused)
+	}
+
+	if len(graph.Missing) != 0 {
+		t.Errorf("Missing = %+v", graph.Missing)
+	}
+}
+
+func TestImportGraphMissingImports(t *testing.T) {
+	defining := OMFModule{
+		Name:       "DEFINING-MIB",
+		Types:      []OMFType{{Name: "DefiningString", BaseType: "OctetString", Decl: "TextualConvention"}},
+		OtherNodes: []OMFNode{{Name: "definingRoot", Oid: "1.3.6.1.4.1.77784"}},
+	}
+
+	using := OMFModule{
+		Name: "USING-MIB",
+		Imports: []OMFImport{
+			{ModName: "DEFINING-MIB", ImportedNodes: []string{"definingRoot", "definingGone"}},
+		},
+		Scalars: []OMFScalar{
+			{OMFNode{Name: "usingName", Oid: "1.3.6.1.4.1.77784.1", Type: &OMFType{Name: "DefiningString"}}},
+		},
+	}
+
+	graph := BuildOmfImportGraph([]OMFModule{using, defining})
+
+	want_unused := []OMFImportRef{{Module: "USING-MIB", ImportedModule: "DEFINING-MIB", Name: "definingGone"}}
+
+	if !slices.Equal(graph.Unused, want_unused) {
+		t.Errorf("Unused = %+v, want %+v", graph.Unused, want_unused)
+	}
+
+	want_missing := []OMFImportRef{
+		{Module: "USING-MIB", ImportedModule: "DEFINING-MIB", Name: "definingGone"},
+		{Module: "USING-MIB", ImportedModule: "DEFINING-MIB", Name: "DefiningString"},
+	}
+
+	if !slices.Equal(graph.Missing, want_missing) {
+		t.Errorf("Missing = %+v, want %+v", graph.Missing, want_missing)
+	}
+}
+
+func TestImportGraphExport(t *testing.T) {
+	graph := OMFImportGraph{
+		Modules:         []string{"A-MIB", "B-MIB"},
+		ExternalModules: []string{"C-MIB"},
+		Edges: []OMFImportEdge{
+			{Module: "A-MIB", ImportedModule: "B-MIB", Names: []string{"bRoot", "BType"}},
+			{Module: "A-MIB", ImportedModule: "C-MIB", Names: []string{"cRoot"}},
+		},
+	}
+
+	dot := GenerateImportGraphDot(graph)
+
+	for _, line := range []string{
+		`  "C-MIB" [style=dashed];`,
+		`  "A-MIB" -> "B-MIB" [tooltip="bRoot, BType"];`,
+		`  "A-MIB" -> "C-MIB" [tooltip="cRoot"];`,
+	} {
+		if !strings.Contains(dot, line+"\n") {
+			t.Errorf("DOT output has no line %s:\n%s", line, dot)
+		}
+	}
+
+	want_mermaid := `graph LR
+  m0["A-MIB"]
+  m1["B-MIB"]
+  m2["C-MIB"]:::external
+  m0 --> m1
+  m0 --> m2
+  classDef external stroke-dasharray: 5 5
+`
+
+	if mermaid := GenerateImportGraphMermaid(graph); mermaid != want_mermaid {
+		t.Errorf("Mermaid output is\n%s\nwant\n%s", mermaid, want_mermaid)
+	}
+}
@@ -0,0 +1,346 @@
+package omifier
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+)
+
+type OMFJsonSchema map[string]any
+
+const json_schema_dialect = "https://json-schema.org/draft/2020-12/schema"
+
+var json_schema_time_type = reflect.TypeOf(time.Time{})
+
+var json_schema_full_ranges = map[string]OMFRange{
+	"Integer32":  {-2147483648, 2147483647},
+	"Unsigned32": {0, 4294967295},
+	"Counter32":  {0, 4294967295},
+	"Gauge32":    {0, 4294967295},
+	"TimeTicks":  {0, 4294967295},
+}
+
+type json_schema_reflector struct {
+	defs map[string]any
+}
+
+func json_schema_nullable(schema OMFJsonSchema) OMFJsonSchema {
+	return OMFJsonSchema{"anyOf": []any{schema, OMFJsonSchema{"type": "null"}}}
+}
+
+func json_field_name(field reflect.StructField) (string, bool, bool) {
+	tag := field.Tag.Get("json")
+
+	if tag == "-" {
+		return "", false, false
+	}
+
+	name, options, _ := strings.Cut(tag, ",")
+
+	if name == "" {
+		name = field.Name
+	}
+
+	return name, strings.Contains(options, "omitempty"), true
+}
+
+func (r *json_schema_reflector) struct_fields(t reflect.Type, properties OMFJsonSchema, required *[]string) {
+	for idx := 0; idx < t.NumField(); idx++ {
+		field := t.Field(idx)
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			r.struct_fields(field.Type, properties, required)
+
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		name, omitempty, ok := json_field_name(field)
+
+		if !ok {
+			continue
+		}
+
+		properties[name] = r.schema(field.Type)
+
+		if !omitempty {
+			*required = append(*required, name)
+		}
+	}
+}
+
+func (r *json_schema_reflector) schema(t reflect.Type) OMFJsonSchema {
+	if t == json_schema_time_type {
+		return OMFJsonSchema{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return json_schema_nullable(r.schema(t.Elem()))
+	case reflect.Struct:
+		if _, defined := r.defs[t.Name()]; !defined {
+			r.defs[t.Name()] = nil
+
+			properties := OMFJsonSchema{}
+
+			required := []string{}
+
+			r.struct_fields(t, properties, &required)
+
+			r.defs[t.Name()] = OMFJsonSchema{
+				"type":                 "object",
+				"properties":           properties,
+				"required":             required,
+				"additionalProperties": false,
+			}
+		}
+
+		return OMFJsonSchema{"$ref": "#/$defs/" + t.Name()}
+	case reflect.Slice:
+		return json_schema_nullable(OMFJsonSchema{"type": "array", "items": r.schema(t.Elem())})
+	case reflect.Array:
+		return OMFJsonSchema{"type": "array", "items": r.schema(t.Elem()), "minItems": t.Len(), "maxItems": t.Len()}
+	case reflect.Map:
+		return json_schema_nullable(OMFJsonSchema{"type": "object", "additionalProperties": r.schema(t.Elem())})
+	case reflect.String:
+		return OMFJsonSchema{"type": "string"}
+	case reflect.Bool:
+		return OMFJsonSchema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return OMFJsonSchema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return OMFJsonSchema{"type": "number"}
+	}
+
+	return OMFJsonSchema{}
+}
+
+func reflect_json_schema(value any, id string) OMFJsonSchema {
+	r := json_schema_reflector{defs: make(map[string]any)}
+
+	root := r.schema(reflect.TypeOf(value))
+
+	return OMFJsonSchema{
+		"$schema": json_schema_dialect,
+		"$id":     id,
+		"$ref":    root["$ref"],
+		"$defs":   r.defs,
+	}
+}
+
+func OmfModuleJsonSchema() OMFJsonSchema {
+	return reflect_json_schema(OMFModule{}, "omf-module.schema.json")
+}
+
+func OmfTreeJsonSchema() OMFJsonSchema {
+	return reflect_json_schema(OMFTree{}, "omf-tree.schema.json")
+}
+
+func OmfCompleteModuleTreeJsonSchema() OMFJsonSchema {
+	return reflect_json_schema(OMFCompleteModuleTree{}, "omf-complete-module-tree.schema.json")
+}
+
+func json_schema_ranges(tp *OMFType, min_keyword string, max_keyword string) []OMFJsonSchema {
+	var ranges []OMFJsonSchema
+
+	for _, rg := range tp.Ranges {
+		rg_schema := OMFJsonSchema{min_keyword: rg[0]}
+
+		if rg[1] == -1 && (tp.BaseType == "Unsigned64" || tp.Name == "Counter64") {
+			rg_schema[max_keyword] = uint64(18446744073709551615)
+		} else {
+			rg_schema[max_keyword] = rg[1]
+		}
+
+		ranges = append(ranges, rg_schema)
+	}
+
+	return ranges
+}
+
+func json_schema_apply_ranges(schema OMFJsonSchema, ranges []OMFJsonSchema) {
+	if len(ranges) == 1 {
+		for keyword, value := range ranges[0] {
+			schema[keyword] = value
+		}
+	}
+
+	if len(ranges) > 1 {
+		var any_of []any
+
+		for _, rg := range ranges {
+			any_of = append(any_of, rg)
+		}
+
+		schema["anyOf"] = any_of
+	}
+}
+
+func json_schema_for_type(tp *OMFType) OMFJsonSchema {
+	if tp == nil {
+		return OMFJsonSchema{}
+	}
+
+	switch {
+	case tp.BaseType == "Enum":
+		var one_of []any
+
+		for _, label := range smi_ordered_enum(tp.Enum) {
+			one_of = append(one_of, OMFJsonSchema{"const": tp.Enum[label], "title": label})
+		}
+
+		return OMFJsonSchema{"type": "integer", "oneOf": one_of}
+	case tp.BaseType == "Bits":
+		return OMFJsonSchema{"type": "array", "uniqueItems": true, "items": OMFJsonSchema{"enum": smi_ordered_enum(tp.Enum)}}
+	case tp.Name == "IpAddress":
+		return OMFJsonSchema{"type": "string", "format": "ipv4"}
+	case tp.Name == "MacAddress" || tp.Name == "PhysAddress":
+		return OMFJsonSchema{"type": "string", "pattern": "^([0-9a-fA-F]{2}(:[0-9a-fA-F]{2})*)?$"}
+	case tp.BaseType == "ObjectIdentifier":
+		return OMFJsonSchema{"type": "string", "pattern": "^[0-9]+(\\.[0-9]+)*$"}
+	case tp.BaseType == "OctetString":
+		schema := OMFJsonSchema{"type": "string"}
+
+		if !yang_format_string_regex.MatchString(tp.Format) && tp.Name != "DisplayString" && tp.Name != "SnmpAdminString" {
+			schema["contentEncoding"] = "base64"
+
+			return schema
+		}
+
+		json_schema_apply_ranges(schema, json_schema_ranges(tp, "minLength", "maxLength"))
+
+		return schema
+	}
+
+	schema := OMFJsonSchema{"type": "integer"}
+
+	if full_range, ok := json_schema_full_ranges[tp.Name]; ok && (len(tp.Ranges) == 0 || (len(tp.Ranges) == 1 && tp.Ranges[0] == full_range)) {
+		schema["minimum"], schema["maximum"] = full_range[0], full_range[1]
+
+		return schema
+	}
+
+	if tp.Name == "Counter64" || tp.BaseType == "Unsigned64" {
+		schema["minimum"], schema["maximum"] = 0, uint64(18446744073709551615)
+
+		return schema
+	}
+
+	json_schema_apply_ranges(schema, json_schema_ranges(tp, "minimum", "maximum"))
+
+	return schema
+}
+
+func json_schema_for_node(nd *OMFNode) OMFJsonSchema {
+	schema := json_schema_for_type(nd.Type)
+
+	if nd.Description != "" {
+		schema["description"] = normalize_smi_text(nd.Description)
+	}
+
+	schema["x-oid"] = nd.Oid
+
+	if nd.Access == "ReadOnly" {
+		schema["readOnly"] = true
+	}
+
+	if nd.Status == "Deprecated" || nd.Status == "Obsolete" {
+		schema["deprecated"] = true
+	}
+
+	return schema
+}
+
+func create_module_json_schema(mod *OMFModule) OMFJsonSchema {
+	properties := OMFJsonSchema{}
+
+	for _, sc := range mod.Scalars {
+		if sc.Access == "NotAccessible" || sc.Access == "Notify" {
+			continue
+		}
+
+		properties[sc.Name] = json_schema_for_node(&sc.OMFNode)
+	}
+
+	for _, tb := range mod.Tables {
+		row_properties := OMFJsonSchema{}
+
+		var required []string
+
+		for _, idx := range tb.Indexes {
+			row_properties[idx.Name] = json_schema_for_node(&idx.OMFNode)
+
+			required = append(required, idx.Name)
+		}
+
+		for _, col := range tb.Columns {
+			if _, ok := row_properties[col.Name]; ok {
+				continue
+			}
+
+			row_properties[col.Name] = json_schema_for_node(&col)
+		}
+
+		row := OMFJsonSchema{
+			"type":                 "object",
+			"title":                tb.Entry.Name,
+			"x-oid":                tb.Entry.Oid,
+			"properties":           row_properties,
+			"additionalProperties": false,
+		}
+
+		if len(required) > 0 {
+			row["required"] = required
+		}
+
+		table := OMFJsonSchema{
+			"type":  "array",
+			"items": row,
+			"x-oid": tb.Oid,
+		}
+
+		if tb.Description != "" {
+			table["description"] = normalize_smi_text(tb.Description)
+		}
+
+		properties[tb.Name] = table
+	}
+
+	schema := OMFJsonSchema{
+		"$schema":              json_schema_dialect,
+		"$id":                  fmt.Sprintf("%s.schema.json", mod.Name),
+		"title":                mod.Name,
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+
+	if mod.Description != "" {
+		schema["description"] = normalize_smi_text(mod.Description)
+	}
+
+	return schema
+}
+
+func GenerateModuleJsonSchema(mod OMFModule) (OMFJsonSchema, error) {
+	if mod.Name == "" {
+		return nil, errors.New("cannot generate a json schema for an unnamed module")
+	}
+
+	return create_module_json_schema(&mod), nil
+}
+
+func WriteJsonSchema(out io.Writer, schema OMFJsonSchema) error {
+	encoder := json.NewEncoder(out)
+
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(schema)
+}
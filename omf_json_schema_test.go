@@ -0,0 +1,129 @@
+package omifier
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func omf_json_schema_def(t *testing.T, schema OMFJsonSchema, name string) (OMFJsonSchema, []string) {
+	t.Helper()
+
+	def, ok := schema["$defs"].(map[string]any)[name].(OMFJsonSchema)
+
+	if !ok {
+		t.Fatalf("schema has no definition of %s", name)
+	}
+
+	return def["properties"].(OMFJsonSchema), def["required"].([]string)
+}
+
+func TestOmfModuleJsonSchemaMatchesEncodedModule(t *testing.T) {
+	mod, err := GetOmfCommomStruct(omf_testdata_path, "ACME-TEST-MIB", false)
+
+	if err != nil {
+		t.Fatalf("GetOmfCommomStruct: %s", err)
+	}
+
+	schema := OmfModuleJsonSchema()
+
+	if schema["$ref"] != "#/$defs/OMFModule" {
+		t.Fatalf("module schema refers to %v", schema["$ref"])
+	}
+
+	encoded, err := json.Marshal(mod)
+
+	if err != nil {
+		t.Fatalf("json.Marshal: %s", err)
+	}
+
+	var decoded map[string]any
+
+	err = json.Unmarshal(encoded, &decoded)
+
+	if err != nil {
+		t.Fatalf("json.Unmarshal: %s", err)
+	}
+
+	properties, required := omf_json_schema_def(t, schema, "OMFModule")
+
+	for key := range decoded {
+		if _, ok := properties[key]; !ok {
+			t.Errorf("encoded module has %s, which the schema does not allow", key)
+		}
+	}
+
+	for _, key := range required {
+		if _, ok := decoded[key]; !ok {
+			t.Errorf("schema requires %s, which the encoded module lacks", key)
+		}
+	}
+
+	node_properties, _ := omf_json_schema_def(t, schema, "OMFNode")
+
+	if _, ok := node_properties["Oid"]; !ok {
+		t.Errorf("OMFNode schema has no Oid property")
+	}
+
+	tree_properties, _ := omf_json_schema_def(t, OmfTreeJsonSchema(), "OMFTreeNode")
+
+	if _, ok := tree_properties["Children"]; !ok {
+		t.Errorf("OMFTreeNode schema has no Children property")
+	}
+
+	omf_json_schema_def(t, OmfCompleteModuleTreeJsonSchema(), "OMFCompleteModuleTree")
+}
+
+func TestGenerateModuleJsonSchema(t *testing.T) {
+	mod, err := GetOmfCommomStruct(omf_testdata_path, "ACME-TEST-MIB", false)
+
+	if err != nil {
+		t.Fatalf("GetOmfCommomStruct: %s", err)
+	}
+
+	schema, err := GenerateModuleJsonSchema(mod)
+
+	if err != nil {
+		t.Fatalf("GenerateModuleJsonSchema: %s", err)
+	}
+
+	properties := schema["properties"].(OMFJsonSchema)
+
+	system_name := properties["acmeSystemName"].(OMFJsonSchema)
+
+	if system_name["type"] != "string" || system_name["maxLength"] != int64(64) || system_name["x-oid"] != "1.3.6.1.4.1.99999.1.1" {
+		t.Errorf("acmeSystemName schema = %v", system_name)
+	}
+
+	if legacy := properties["acmeLegacyCounter"].(OMFJsonSchema); legacy["minimum"] != int64(1) || legacy["maximum"] != int64(65535) {
+		t.Errorf("acmeLegacyCounter schema = %v", legacy)
+	}
+
+	table := properties["acmePortTable"].(OMFJsonSchema)
+
+	if table["type"] != "array" {
+		t.Fatalf("acmePortTable schema = %v", table)
+	}
+
+	row := table["items"].(OMFJsonSchema)
+
+	if !slices.Equal(row["required"].([]string), []string{"acmePortIndex"}) {
+		t.Errorf("acmePortEntry requires %v", row["required"])
+	}
+
+	row_properties := row["properties"].(OMFJsonSchema)
+
+	state := row_properties["acmePortState"].(OMFJsonSchema)
+
+	if one_of, ok := state["oneOf"].([]any); !ok || len(one_of) != 3 || one_of[1].(OMFJsonSchema)["title"] != "up" {
+		t.Errorf("acmePortState schema = %v", state)
+	}
+
+	if mac := row_properties["acmePortMac"].(OMFJsonSchema); mac["pattern"] == nil {
+		t.Errorf("acmePortMac schema = %v", mac)
+	}
+
+	if _, err := json.Marshal(schema); err != nil {
+		t.Errorf("module schema does not encode: %s", err)
+	}
+}
@@ -0,0 +1,301 @@
+package omifier
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+type OMFLintSeverity string
+
+const (
+	OMFLintError   OMFLintSeverity = "Error"
+	OMFLintWarning OMFLintSeverity = "Warning"
+	OMFLintInfo    OMFLintSeverity = "Info"
+)
+
+type OMFLintFinding struct {
+	Rule     string
+	Severity OMFLintSeverity
+	Module   string
+	Object   string `json:",omitempty"`
+	Oid      string `json:",omitempty"`
+	Message  string
+}
+
+type OMFLintReport struct {
+	Module   string
+	Findings []OMFLintFinding
+}
+
+type omf_linter struct {
+	module   *OMFModule
+	smiv2    bool
+	findings []OMFLintFinding
+}
+
+func (l *omf_linter) report(rule string, severity OMFLintSeverity, nd *OMFNode, format string, args ...any) {
+	finding := OMFLintFinding{
+		Rule:     rule,
+		Severity: severity,
+		Module:   l.module.Name,
+		Message:  fmt.Sprintf(format, args...),
+	}
+
+	if nd != nil {
+		finding.Object = nd.Name
+
+		finding.Oid = nd.Oid
+	}
+
+	l.findings = append(l.findings, finding)
+}
+
+func omf_lint_current(status string) bool {
+	return status == "Current" || status == "Mandatory"
+}
+
+func (l *omf_linter) check_descriptions() {
+	severity := OMFLintWarning
+
+	if !l.smiv2 {
+		severity = OMFLintInfo
+	}
+
+	for _, nd := range omf_module_nodes(l.module) {
+		if nd.Description != "" || nd.Decl == "ValueAssignment" || nd.Decl == "ModuleIdentity" {
+			continue
+		}
+
+		l.report("missing-description", severity, &nd, "%s has no DESCRIPTION", nd.Name)
+	}
+
+	for _, tp := range l.module.Types {
+		if tp.Decl == "TextualConvention" && tp.Description == "" {
+			l.report("missing-description", severity, &OMFNode{Name: tp.Name}, "textual convention %s has no DESCRIPTION", tp.Name)
+		}
+	}
+}
+
+func (l *omf_linter) check_tables() {
+	for _, tb := range l.module.Tables {
+		if len(tb.Indexes) == 0 {
+			l.report("missing-index", OMFLintError, &tb.OMFNode, "table %s has no INDEX or AUGMENTS clause", tb.Name)
+		}
+
+		indexes := make(map[string]bool)
+
+		for _, idx := range tb.Indexes {
+			indexes[idx.Name] = true
+		}
+
+		for _, col := range tb.Columns {
+			if col.Access == "NotAccessible" && !indexes[col.Name] {
+				l.report("not-accessible-misuse", OMFLintError, &col, "column %s is not-accessible but is not part of the INDEX of %s", col.Name, tb.Name)
+			}
+		}
+
+		entry_name := strings.TrimSuffix(tb.Name, "Table") + "Entry"
+
+		switch {
+		case !strings.HasSuffix(tb.Name, "Table"):
+			l.report("naming", OMFLintWarning, &tb.OMFNode, "table name %s does not end with Table", tb.Name)
+		case tb.Entry.Name != "" && tb.Entry.Name != entry_name:
+			l.report("naming", OMFLintWarning, &tb.Entry, "row %s of table %s is expected to be named %s", tb.Entry.Name, tb.Name, entry_name)
+		}
+	}
+
+	for _, nf := range l.module.Notifications {
+		for _, obj := range nf.Objects {
+			if obj.Access == "NotAccessible" {
+				l.report("not-accessible-misuse", OMFLintError, &nf.OMFNode, "notification %s carries the not-accessible object %s", nf.Name, obj.Name)
+			}
+		}
+	}
+
+	for _, gr := range l.module.Groups {
+		for _, member := range gr.Members {
+			if member.Access == "NotAccessible" {
+				l.report("not-accessible-misuse", OMFLintError, &gr.OMFNode, "group %s lists the not-accessible object %s", gr.Name, member.Name)
+			}
+		}
+	}
+}
+
+func (l *omf_linter) check_enum(tp *OMFType, nd *OMFNode) {
+	if tp.BaseType != "Enum" {
+		return
+	}
+
+	for _, label := range smi_ordered_enum(tp.Enum) {
+		if tp.Enum[label] == 0 {
+			l.report("enum-zero", OMFLintWarning, nd, "enumeration label %s of %s has the value 0", label, nd.Name)
+		}
+	}
+}
+
+func (l *omf_linter) check_types() {
+	for _, tp := range l.module.Types {
+		l.check_enum(&tp, &OMFNode{Name: tp.Name})
+	}
+
+	for _, nd := range omf_module_nodes(l.module) {
+		tp := nd.Type
+
+		if tp == nil {
+			continue
+		}
+
+		if tp.Decl == "ImplicitType" {
+			l.check_enum(tp, &nd)
+		}
+
+		if (tp.Name == "Counter32" || tp.Name == "Counter64") && tp.Units == "" {
+			l.report("counter-without-units", OMFLintInfo, &nd, "counter %s has no UNITS clause", nd.Name)
+		}
+
+		if omf_lint_current(nd.Status) && (tp.Status == "Deprecated" || tp.Status == "Obsolete") {
+			l.report("deprecated-type", OMFLintWarning, &nd, "current object %s uses the %s type %s", nd.Name, strings.ToLower(tp.Status), tp.Name)
+		}
+	}
+}
+
+func (l *omf_linter) check_groups() {
+	if !l.smiv2 {
+		return
+	}
+
+	grouped := make(map[string]bool)
+
+	for _, gr := range l.module.Groups {
+		for _, member := range gr.Members {
+			grouped[member.Name] = true
+		}
+	}
+
+	var objects []OMFNode
+
+	for _, sc := range l.module.Scalars {
+		objects = append(objects, sc.OMFNode)
+	}
+
+	for _, tb := range l.module.Tables {
+		objects = append(objects, tb.Columns...)
+	}
+
+	for _, nf := range l.module.Notifications {
+		objects = append(objects, nf.OMFNode)
+	}
+
+	for _, obj := range objects {
+		if obj.Access == "NotAccessible" || grouped[obj.Name] {
+			continue
+		}
+
+		l.report("not-in-group", OMFLintWarning, &obj, "%s is not a member of any OBJECT-GROUP or NOTIFICATION-GROUP", obj.Name)
+	}
+}
+
+func (l *omf_linter) check_names() {
+	for _, nd := range omf_module_nodes(l.module) {
+		if nd.Name == "" {
+			continue
+		}
+
+		switch {
+		case !unicode.IsLower([]rune(nd.Name)[0]):
+			l.report("naming", OMFLintError, &nd, "object name %s must start with a lowercase letter", nd.Name)
+		case l.smiv2 && strings.Contains(nd.Name, "-"):
+			l.report("naming", OMFLintWarning, &nd, "object name %s contains a hyphen", nd.Name)
+		}
+
+		switch {
+		case len(nd.Name) > 64:
+			l.report("naming", OMFLintError, &nd, "object name %s is longer than 64 characters", nd.Name)
+		case len(nd.Name) > 32:
+			l.report("naming", OMFLintInfo, &nd, "object name %s is longer than 32 characters", nd.Name)
+		}
+	}
+
+	for _, tp := range l.module.Types {
+		if tp.Name != "" && !unicode.IsUpper([]rune(tp.Name)[0]) {
+			l.report("naming", OMFLintError, &OMFNode{Name: tp.Name}, "type name %s must start with an uppercase letter", tp.Name)
+		}
+	}
+}
+
+func LintOmfModule(mod OMFModule) OMFLintReport {
+	l := omf_linter{module: &mod, smiv2: mod.Language == "SMIv2", findings: []OMFLintFinding{}}
+
+	l.check_descriptions()
+
+	l.check_tables()
+
+	l.check_types()
+
+	l.check_groups()
+
+	l.check_names()
+
+	return OMFLintReport{Module: mod.Name, Findings: l.findings}
+}
+
+func GetOmfLintReport(path string, module_name string) (OMFLintReport, error) {
+	mod, err := GetOmfCommomStruct(path, module_name, false)
+
+	if err != nil {
+		return OMFLintReport{Module: module_name}, err
+	}
+
+	return LintOmfModule(mod), nil
+}
+
+func (r OMFLintReport) Count(severity OMFLintSeverity) int {
+	count := 0
+
+	for _, finding := range r.Findings {
+		if finding.Severity == severity {
+			count++
+		}
+	}
+
+	return count
+}
+
+func GenerateLintReportText(report OMFLintReport) string {
+	var out strings.Builder
+
+	for _, finding := range report.Findings {
+		location := finding.Module
+
+		if finding.Object != "" {
+			location += "::" + finding.Object
+		}
+
+		if finding.Oid != "" {
+			location += " (" + finding.Oid + ")"
+		}
+
+		fmt.Fprintf(&out, "%s: %s [%s] %s\n", location, strings.ToLower(string(finding.Severity)), finding.Rule, finding.Message)
+	}
+
+	fmt.Fprintf(&out, "%s: %d errors, %d warnings, %d infos\n", report.Module, report.Count(OMFLintError), report.Count(OMFLintWarning), report.Count(OMFLintInfo))
+
+	return out.String()
+}
+
+func WriteLintReportText(out io.Writer, report OMFLintReport) error {
+	_, err := io.WriteString(out, GenerateLintReportText(report))
+
+	return err
+}
+
+func WriteLintReportJson(out io.Writer, report OMFLintReport) error {
+	encoder := json.NewEncoder(out)
+
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(report)
+}
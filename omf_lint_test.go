@@ -0,0 +1,168 @@
+package omifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func omf_lint_findings(report OMFLintReport) map[string]OMFLintFinding {
+	findings := make(map[string]OMFLintFinding)
+
+	for _, finding := range report.Findings {
+		findings[finding.Rule+" "+finding.Object] = finding
+	}
+
+	return findings
+}
+
+func TestLintRules(t *testing.T) {
+	index := OMFNode{Name: "lintIndex", Oid: "1.3.6.1.4.1.77785.2.1.1", Access: "NotAccessible", Status: "Current", Description: "Index."}
+
+	hidden := OMFNode{Name: "lintHidden", Oid: "1.3.6.1.4.1.77785.2.1.2", Access: "NotAccessible", Status: "Current", Description: "Hidden."}
+
+	mod := OMFModule{
+		Name:     "LINT-MIB",
+		Language: "SMIv2",
+		Types: []OMFType{
+			{Name: "LintState", BaseType: "Enum", Decl: "TextualConvention", Status: "Current", Description: "State.", Enum: map[string]int64{"unknown": 0, "up": 1}},
+			{Name: "lowerType", BaseType: "OctetString", Decl: "TextualConvention", Status: "Current", Description: "Lower."},
+		},
+		Scalars: []OMFScalar{
+			{OMFNode{Name: "lintPackets", Oid: "1.3.6.1.4.1.77785.1.1", Access: "ReadOnly", Status: "Current", Type: &OMFType{Name: "Counter32", BaseType: "Unsigned32"}}},
+			{OMFNode{Name: "lintOld", Oid: "1.3.6.1.4.1.77785.1.2", Access: "ReadOnly", Status: "Current", Description: "Old.", Type: &OMFType{Name: "OldString", BaseType: "OctetString", Status: "Deprecated"}}},
+			{OMFNode{Name: "Lint-Bad", Oid: "1.3.6.1.4.1.77785.1.3", Access: "ReadOnly", Status: "Current", Description: "Bad."}},
+		},
+		Tables: []OMFTable{
+			{
+				OMFNode: OMFNode{Name: "lintTable", Oid: "1.3.6.1.4.1.77785.2", Access: "NotAccessible", Status: "Current", Description: "Table."},
+				Entry:   OMFNode{Name: "lintRow", Oid: "1.3.6.1.4.1.77785.2.1", Access: "NotAccessible", Status: "Current", Description: "Row."},
+				Columns: []OMFNode{index, hidden},
+				Indexes: []OMFIndex{{index}},
+			},
+			{
+				OMFNode: OMFNode{Name: "lintList", Oid: "1.3.6.1.4.1.77785.3", Access: "NotAccessible", Status: "Current", Description: "List."},
+				Entry:   OMFNode{Name: "lintListEntry", Oid: "1.3.6.1.4.1.77785.3.1", Access: "NotAccessible", Status: "Current", Description: "Entry."},
+			},
+		},
+		Groups: []OMFGroup{
+			{OMFNode: OMFNode{Name: "lintGroup", Oid: "1.3.6.1.4.1.77785.4.1", Status: "Current", Description: "Group."}, Members: []OMFNode{{Name: "lintPackets"}, hidden}},
+		},
+	}
+
+	report := LintOmfModule(mod)
+
+	findings := omf_lint_findings(report)
+
+	tests := []struct {
+		rule     string
+		object   string
+		severity OMFLintSeverity
+	}{
+		{"missing-description", "lintPackets", OMFLintWarning},
+		{"missing-index", "lintList", OMFLintError},
+		{"not-accessible-misuse", "lintHidden", OMFLintError},
+		{"not-accessible-misuse", "lintGroup", OMFLintError},
+		{"naming", "lintRow", OMFLintWarning},
+		{"naming", "lintList", OMFLintWarning},
+		{"naming", "Lint-Bad", OMFLintError},
+		{"naming", "lowerType", OMFLintError},
+		{"enum-zero", "LintState", OMFLintWarning},
+		{"counter-without-units", "lintPackets", OMFLintInfo},
+		{"deprecated-type", "lintOld", OMFLintWarning},
+		{"not-in-group", "lintOld", OMFLintWarning},
+	}
+
+	for _, tt := range tests {
+		finding, ok := findings[tt.rule+" "+tt.object]
+
+		if !ok {
+			t.Errorf("no %s finding for %s", tt.rule, tt.object)
+
+			continue
+		}
+
+		if finding.Severity != tt.severity || finding.Module != "LINT-MIB" {
+			t.Errorf("%s finding for %s is %+v, want severity %s", tt.rule, tt.object, finding, tt.severity)
+		}
+	}
+
+	for _, object := range []string{"lintPackets", "lintIndex", "lintHidden"} {
+		if _, ok := findings["not-in-group "+object]; ok {
+			t.Errorf("%s is reported as not in any group", object)
+		}
+	}
+
+	if findings["missing-index lintList"].Oid != "1.3.6.1.4.1.77785.3" {
+		t.Errorf("missing-index finding carries no location: %+v", findings["missing-index lintList"])
+	}
+}
+
+func TestLintSMIv1Severities(t *testing.T) {
+	mod := OMFModule{
+		Name:     "LINT-V1-MIB",
+		Language: "SMIv1",
+		Scalars: []OMFScalar{
+			{OMFNode{Name: "lintV1Name", Oid: "1.3.6.1.4.1.77786.1", Access: "ReadOnly", Status: "Mandatory"}},
+		},
+	}
+
+	findings := omf_lint_findings(LintOmfModule(mod))
+
+	if findings["missing-description lintV1Name"].Severity != OMFLintInfo {
+		t.Errorf("SMIv1 missing-description is %+v, want an Info finding", findings["missing-description lintV1Name"])
+	}
+
+	if _, ok := findings["not-in-group lintV1Name"]; ok {
+		t.Errorf("SMIv1 objects are checked for group membership")
+	}
+}
+
+func TestLintReportOutput(t *testing.T) {
+	report, err := GetOmfLintReport(omf_testdata_path, "ACME-TEST-MIB")
+
+	if err != nil {
+		t.Fatalf("GetOmfLintReport: %s", err)
+	}
+
+	if report.Module != "ACME-TEST-MIB" || report.Count(OMFLintError) != 0 {
+		t.Errorf("ACME-TEST-MIB lints with %d errors: %+v", report.Count(OMFLintError), report.Findings)
+	}
+
+	text := GenerateLintReportText(OMFLintReport{
+		Module: "LINT-MIB",
+		Findings: []OMFLintFinding{
+			{Rule: "missing-index", Severity: OMFLintError, Module: "LINT-MIB", Object: "lintList", Oid: "1.3.6.1.4.1.77785.3", Message: "table lintList has no INDEX or AUGMENTS clause"},
+			{Rule: "naming", Severity: OMFLintWarning, Module: "LINT-MIB", Message: "module naming"},
+		},
+	})
+
+	want := "LINT-MIB::lintList (1.3.6.1.4.1.77785.3): error [missing-index] table lintList has no INDEX or AUGMENTS clause\n" +
+		"LINT-MIB: warning [naming] module naming\n" +
+		"LINT-MIB: 1 errors, 1 warnings, 0 infos\n"
+
+	if text != want {
+		t.Errorf("GenerateLintReportText =\n%s\nwant\n%s", text, want)
+	}
+
+	var out bytes.Buffer
+
+	err = WriteLintReportJson(&out, report)
+
+	if err != nil {
+		t.Fatalf("WriteLintReportJson: %s", err)
+	}
+
+	var decoded OMFLintReport
+
+	err = json.Unmarshal(out.Bytes(), &decoded)
+
+	if err != nil || decoded.Module != report.Module || len(decoded.Findings) != len(report.Findings) {
+		t.Errorf("JSON report does not round trip: %v\n%s", err, out.String())
+	}
+
+	if !strings.Contains(out.String(), `"Findings": [`) {
+		t.Errorf("JSON report has no Findings list:\n%s", out.String())
+	}
+}
@@ -0,0 +1,336 @@
+package omifier
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	gosmi "github.com/belqlabs/omf-gosmi"
+)
+
+var mib_definitions_regex = regexp.MustCompile(`(?m)^\s*([A-Za-z][A-Za-z0-9-]*)\s+DEFINITIONS\s*::=\s*BEGIN`)
+
+var ErrMibMountExists = errors.New("mib source already mounted")
+
+var mib_mounts = struct {
+	sync.Mutex
+	sources map[string]fs.ReadDirFS
+	names   map[string]string
+	next    int
+}{sources: make(map[string]fs.ReadDirFS), names: make(map[string]string)}
+
+// A mounted MIB source. Path is accepted by every entry point that takes a
+// MIB directory; Close unmounts the source again.
+type OMFMibMount struct {
+	Path    string
+	Modules []string `json:",omitempty"`
+}
+
+func (m *OMFMibMount) Close() error {
+	UnmountMibFS(m.Path)
+
+	return nil
+}
+
+// gosmi only lists the top directory of a source, so every file below it is
+// listed there under its base name. The first file found in walk order wins.
+type mib_source_fs struct {
+	fsys    fs.FS
+	files   map[string]string
+	entries []fs.DirEntry
+}
+
+func new_mib_source_fs(fsys fs.FS) (*mib_source_fs, error) {
+	source := &mib_source_fs{fsys: fsys, files: make(map[string]string)}
+
+	err := fs.WalkDir(fsys, ".", func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if path != "." && strings.HasPrefix(entry.Name(), ".") {
+			if entry.IsDir() {
+				return fs.SkipDir
+			}
+
+			return nil
+		}
+
+		if entry.IsDir() {
+			return nil
+		}
+
+		if _, ok := source.files[entry.Name()]; !ok {
+			source.files[entry.Name()] = path
+
+			source.entries = append(source.entries, entry)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(source.entries, func(i, j int) bool { return source.entries[i].Name() < source.entries[j].Name() })
+
+	return source, nil
+}
+
+func (m *mib_source_fs) Open(name string) (fs.File, error) {
+	path, ok := m.files[name]
+
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	return m.fsys.Open(path)
+}
+
+func (m *mib_source_fs) ReadDir(name string) ([]fs.DirEntry, error) {
+	if name != "." {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+
+	return slices.Clone(m.entries), nil
+}
+
+type memory_mib struct {
+	name     string
+	contents []byte
+}
+
+func (m *memory_mib) Name() string               { return m.name }
+func (m *memory_mib) Size() int64                { return int64(len(m.contents)) }
+func (m *memory_mib) Mode() fs.FileMode          { return 0444 }
+func (m *memory_mib) ModTime() time.Time         { return time.Time{} }
+func (m *memory_mib) IsDir() bool                { return false }
+func (m *memory_mib) Sys() any                   { return nil }
+func (m *memory_mib) Type() fs.FileMode          { return 0 }
+func (m *memory_mib) Info() (fs.FileInfo, error) { return m, nil }
+
+type memory_mib_file struct {
+	*bytes.Reader
+	mib *memory_mib
+}
+
+func (f memory_mib_file) Stat() (fs.FileInfo, error) { return f.mib, nil }
+func (f memory_mib_file) Close() error               { return nil }
+
+// A flat, read only directory of MIB files held in memory.
+type memory_mib_fs map[string]*memory_mib
+
+func (m memory_mib_fs) Open(name string) (fs.File, error) {
+	mib, ok := m[name]
+
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	return memory_mib_file{Reader: bytes.NewReader(mib.contents), mib: mib}, nil
+}
+
+func (m memory_mib_fs) ReadDir(name string) ([]fs.DirEntry, error) {
+	if name != "." {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+
+	var entries []fs.DirEntry
+
+	for _, mib := range m {
+		entries = append(entries, mib)
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	return entries, nil
+}
+
+func mounted_mib_fs(path string) (fs.ReadDirFS, bool) {
+	mib_mounts.Lock()
+	defer mib_mounts.Unlock()
+
+	fsys, ok := mib_mounts.sources[path]
+
+	return fsys, ok
+}
+
+// Each mount gets its own path, so a source is never replaced by a later
+// mount; a name stays taken until its source is unmounted.
+// A unique mount takes the first free "name-N" when name is taken, for
+// sources mounted internally under a name callers may also use.
+func mount_mib_source(name string, fsys fs.ReadDirFS, unique bool) (*OMFMibMount, error) {
+	if name == "" || strings.ContainsAny(name, "/[]#") {
+		return nil, fmt.Errorf("%q is not a valid mib source name", name)
+	}
+
+	mib_mounts.Lock()
+	defer mib_mounts.Unlock()
+
+	mib_mounts.next++
+
+	if _, ok := mib_mounts.names[name]; ok {
+		if !unique {
+			return nil, fmt.Errorf("%s: %w", name, ErrMibMountExists)
+		}
+
+		for {
+			if _, ok := mib_mounts.names[fmt.Sprintf("%s-%d", name, mib_mounts.next)]; !ok {
+				break
+			}
+
+			mib_mounts.next++
+		}
+
+		name = fmt.Sprintf("%s-%d", name, mib_mounts.next)
+	}
+
+	path := fmt.Sprintf("[%s#%d]", name, mib_mounts.next)
+
+	mib_mounts.sources[path] = fsys
+
+	mib_mounts.names[name] = path
+
+	return &OMFMibMount{Path: path}, nil
+}
+
+func MountMibFS(name string, fsys fs.FS) (*OMFMibMount, error) {
+	source, err := new_mib_source_fs(fsys)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return mount_mib_source(name, source, false)
+}
+
+func new_memory_mib_fs(name string, mibs [][]byte) (memory_mib_fs, []string, error) {
+	memory_fs := make(memory_mib_fs)
+
+	var module_names []string
+
+	for idx, contents := range mibs {
+		match := mib_definitions_regex.FindSubmatch(contents)
+
+		if match == nil {
+			return nil, nil, fmt.Errorf("mib %d of %s has no DEFINITIONS ::= BEGIN header", idx, name)
+		}
+
+		module_name := string(match[1])
+
+		if _, ok := memory_fs[module_name]; ok {
+			return nil, nil, fmt.Errorf("mib %d of %s defines %s again", idx, name, module_name)
+		}
+
+		memory_fs[module_name] = &memory_mib{name: module_name, contents: contents}
+
+		module_names = append(module_names, module_name)
+	}
+
+	return memory_fs, module_names, nil
+}
+
+func MountMibBytes(name string, mibs ...[]byte) (*OMFMibMount, error) {
+	memory_fs, module_names, err := new_memory_mib_fs(name, mibs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	mount, err := mount_mib_source(name, memory_fs, false)
+
+	if err != nil {
+		return nil, err
+	}
+
+	mount.Modules = module_names
+
+	return mount, nil
+}
+
+func MountMibReader(name string, r io.Reader) (*OMFMibMount, error) {
+	contents, err := io.ReadAll(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return MountMibBytes(name, contents)
+}
+
+func MountMibZip(name string, r io.ReaderAt, size int64) (*OMFMibMount, error) {
+	archive, err := zip.NewReader(r, size)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return MountMibFS(name, archive)
+}
+
+func UnmountMibFS(path string) {
+	mib_mounts.Lock()
+	defer mib_mounts.Unlock()
+
+	delete(mib_mounts.sources, path)
+
+	for name, mounted := range mib_mounts.names {
+		if mounted == path {
+			delete(mib_mounts.names, name)
+		}
+	}
+}
+
+// gosmi puts the brackets back around the name of a source.
+func mib_mount_name(path string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(path, "["), "]")
+}
+
+func append_mib_path(path string) {
+	if fsys, ok := mounted_mib_fs(path); ok {
+		gosmi.AppendFS(gosmi.NamedFS(mib_mount_name(path), fsys))
+
+		return
+	}
+
+	gosmi.AppendPath(path)
+}
+
+func prepend_mib_path(path string) {
+	if fsys, ok := mounted_mib_fs(path); ok {
+		gosmi.PrependFS(gosmi.NamedFS(mib_mount_name(path), fsys))
+
+		return
+	}
+
+	gosmi.PrependPath(path)
+}
+
+// gosmi names files of a mounted source "[name]/file", which is resolved
+// back against the mount before falling back to the local filesystem.
+func open_module_source(path string) (io.ReadCloser, error) {
+	mount, file_name, found := strings.Cut(path, "/")
+
+	if found {
+		if fsys, ok := mounted_mib_fs(mount); ok {
+			return fsys.Open(file_name)
+		}
+	}
+
+	if path == "" {
+		return nil, errors.New("module has no source path")
+	}
+
+	return os.Open(path)
+}
@@ -0,0 +1,190 @@
+package omifier
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+var omf_source_files = map[string]string{
+	"base/SNMPv2-SMI.mib":           "SNMPv2-SMI.mib",
+	"base/tc/SNMPv2-TC.mib":         "SNMPv2-TC.mib",
+	"base/SNMPv2-CONF.mib":          "SNMPv2-CONF.mib",
+	"vendor/acme/ACME-TEST-MIB.mib": "ACME-TEST-MIB.mib",
+}
+
+func omf_read_testdata(t *testing.T, file_name string) []byte {
+	t.Helper()
+
+	contents, err := os.ReadFile(filepath.Join(omf_testdata_path, file_name))
+
+	if err != nil {
+		t.Fatalf("ReadFile: %s", err)
+	}
+
+	return contents
+}
+
+func omf_assert_mounted_acme(t *testing.T, mount *OMFMibMount) {
+	t.Helper()
+
+	mod, err := GetOmfCommomStruct(mount.Path, "ACME-TEST-MIB", false)
+
+	if err != nil {
+		t.Fatalf("GetOmfCommomStruct(%s): %s", mount.Path, err)
+	}
+
+	if mod.Name != "ACME-TEST-MIB" || len(mod.Tables) == 0 {
+		t.Errorf("module loaded from %s is %s with %d tables", mount.Path, mod.Name, len(mod.Tables))
+	}
+
+	if last_updated := mod.LastUpdated.UTC().Format(time.RFC3339); last_updated != "2024-01-15T00:00:00Z" {
+		t.Errorf("LastUpdated read from %s is %s", mount.Path, last_updated)
+	}
+}
+
+func TestMountMibFSWalksSubdirectories(t *testing.T) {
+	fsys := fstest.MapFS{
+		".git/ACME-TEST-MIB.mib": {Data: []byte("not a mib")},
+	}
+
+	for path, file_name := range omf_source_files {
+		fsys[path] = &fstest.MapFile{Data: omf_read_testdata(t, file_name)}
+	}
+
+	mount, err := MountMibFS("omf-test-fs", fsys)
+
+	if err != nil {
+		t.Fatalf("MountMibFS: %s", err)
+	}
+
+	defer mount.Close()
+
+	omf_assert_mounted_acme(t, mount)
+}
+
+func TestMountMibZip(t *testing.T) {
+	var archive bytes.Buffer
+
+	writer := zip.NewWriter(&archive)
+
+	for path, file_name := range omf_source_files {
+		file, err := writer.Create("mibs/" + path)
+
+		if err != nil {
+			t.Fatalf("zip Create: %s", err)
+		}
+
+		file.Write(omf_read_testdata(t, file_name))
+	}
+
+	err := writer.Close()
+
+	if err != nil {
+		t.Fatalf("zip Close: %s", err)
+	}
+
+	mount, err := MountMibZip("omf-test-zip", bytes.NewReader(archive.Bytes()), int64(archive.Len()))
+
+	if err != nil {
+		t.Fatalf("MountMibZip: %s", err)
+	}
+
+	defer mount.Close()
+
+	omf_assert_mounted_acme(t, mount)
+}
+
+func TestMountMibBytesHandles(t *testing.T) {
+	var mibs [][]byte
+
+	for _, file_name := range omf_source_files {
+		mibs = append(mibs, omf_read_testdata(t, file_name))
+	}
+
+	mount, err := MountMibBytes("omf-test-bytes", mibs...)
+
+	if err != nil {
+		t.Fatalf("MountMibBytes: %s", err)
+	}
+
+	modules := slices.Sorted(slices.Values(mount.Modules))
+
+	if want := []string{"ACME-TEST-MIB", "SNMPv2-CONF", "SNMPv2-SMI", "SNMPv2-TC"}; !slices.Equal(modules, want) {
+		t.Errorf("Modules = %v, want %v", modules, want)
+	}
+
+	omf_assert_mounted_acme(t, mount)
+
+	_, err = MountMibBytes("omf-test-bytes", mibs...)
+
+	if !errors.Is(err, ErrMibMountExists) {
+		t.Errorf("mounting a taken name returned %v, want ErrMibMountExists", err)
+	}
+
+	mount.Close()
+
+	if _, ok := mounted_mib_fs(mount.Path); ok {
+		t.Errorf("%s is still mounted after Close", mount.Path)
+	}
+
+	remount, err := MountMibBytes("omf-test-bytes", mibs...)
+
+	if err != nil {
+		t.Fatalf("mounting a released name: %s", err)
+	}
+
+	defer remount.Close()
+
+	if remount.Path == mount.Path {
+		t.Errorf("a new mount reused the path %s", mount.Path)
+	}
+
+	for _, name := range []string{"", "omf/test", "[omf-test]"} {
+		if _, err := MountMibBytes(name, mibs...); err == nil {
+			t.Errorf("MountMibBytes accepted the name %q", name)
+		}
+	}
+
+	if _, err := MountMibBytes("omf-test-invalid", []byte("not a mib")); err == nil {
+		t.Errorf("MountMibBytes accepted a source without DEFINITIONS")
+	}
+
+	if _, err := MountMibBytes("omf-test-duplicate", mibs[0], mibs[0]); err == nil {
+		t.Errorf("MountMibBytes accepted two sources defining the same module")
+	}
+}
+
+func TestMountMibSourceUnique(t *testing.T) {
+	held, err := MountMibBytes("omf-parse-back-ACME-TEST-MIB", omf_read_testdata(t, "ACME-TEST-MIB.mib"))
+
+	if err != nil {
+		t.Fatalf("MountMibBytes: %s", err)
+	}
+
+	defer held.Close()
+
+	unique, err := mount_mib_source("omf-parse-back-ACME-TEST-MIB", make(memory_mib_fs), true)
+
+	if err != nil {
+		t.Fatalf("a unique mount of a taken name: %s", err)
+	}
+
+	defer unique.Close()
+
+	if unique.Path == held.Path {
+		t.Errorf("a unique mount reused the path %s", held.Path)
+	}
+
+	mod, err := GetOmfCommomStruct(omf_testdata_path, "ACME-TEST-MIB", true)
+
+	if err != nil || mod.ParseBack == nil {
+		t.Errorf("parse back with its mount name taken: %v", err)
+	}
+}
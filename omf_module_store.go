@@ -0,0 +1,285 @@
+package omifier
+
+import (
+	"crypto/md5"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+var ErrModuleNotStored = errors.New("module not stored")
+
+const module_store_index_file = "index.json"
+
+const module_store_revision_format = "200601021504Z"
+
+type OMFStoredRevision struct {
+	ModuleName  string
+	Revision    string
+	ModuleHash  string
+	ContentHash string
+	LastUpdated time.Time
+	StoredAt    time.Time
+}
+
+// Modules are kept as <root>/<module>/<revision>.json, next to an index.json
+// that lists the stored revisions so history never has to decode a module.
+type OMFModuleStore struct {
+	root string
+	mu   sync.Mutex
+}
+
+func OpenOmfModuleStore(root string) (*OMFModuleStore, error) {
+	err := os.MkdirAll(root, 0755)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &OMFModuleStore{root: root}, nil
+}
+
+func module_store_revision(mod *OMFModule) string {
+	if mod.LastUpdated.IsZero() {
+		return "unrevisioned-" + mod.ModuleHash
+	}
+
+	return mod.LastUpdated.UTC().Format(module_store_revision_format)
+}
+
+func (s *OMFModuleStore) module_dir(module_name string) (string, error) {
+	if !smi_identifier_regex.MatchString(module_name) {
+		return "", fmt.Errorf("%q is not a valid SMI module identifier", module_name)
+	}
+
+	return filepath.Join(s.root, module_name), nil
+}
+
+func write_file_atomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".omf-*")
+
+	if err != nil {
+		return err
+	}
+
+	_, err = tmp.Write(data)
+
+	if err == nil {
+		err = tmp.Sync()
+	}
+
+	close_err := tmp.Close()
+
+	if err == nil {
+		err = close_err
+	}
+
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+
+	return err
+}
+
+func (s *OMFModuleStore) read_index(module_name string) ([]OMFStoredRevision, error) {
+	dir, err := s.module_dir(module_name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, module_store_index_file))
+
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%s: %w", module_name, ErrModuleNotStored)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var index []OMFStoredRevision
+
+	err = json.Unmarshal(data, &index)
+
+	return index, err
+}
+
+func (s *OMFModuleStore) Put(mod OMFModule) (OMFStoredRevision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dir, err := s.module_dir(mod.Name)
+
+	if err != nil {
+		return OMFStoredRevision{}, err
+	}
+
+	err = os.MkdirAll(dir, 0755)
+
+	if err != nil {
+		return OMFStoredRevision{}, err
+	}
+
+	index, err := s.read_index(mod.Name)
+
+	if err != nil && !errors.Is(err, ErrModuleNotStored) {
+		return OMFStoredRevision{}, err
+	}
+
+	data, err := json.MarshalIndent(mod, "", "  ")
+
+	if err != nil {
+		return OMFStoredRevision{}, err
+	}
+
+	stored := OMFStoredRevision{
+		ModuleName:  mod.Name,
+		Revision:    module_store_revision(&mod),
+		ModuleHash:  mod.ModuleHash,
+		ContentHash: fmt.Sprintf("%x", md5.Sum(data)),
+		LastUpdated: mod.LastUpdated,
+		StoredAt:    time.Now().UTC(),
+	}
+
+	// ModuleHash only covers the module header, so the serialized module
+	// decides whether it changed. A module changed without a new REVISION
+	// keeps its earlier content and is stored next to it under a revision
+	// suffixed with its content hash.
+	taken := false
+
+	for _, rev := range index {
+		if rev.ContentHash == stored.ContentHash && (rev.Revision == stored.Revision || rev.Revision == stored.Revision+"-"+stored.ContentHash) {
+			return rev, nil
+		}
+
+		if rev.Revision == stored.Revision {
+			taken = true
+		}
+	}
+
+	if taken {
+		stored.Revision += "-" + stored.ContentHash
+	}
+
+	err = write_file_atomic(filepath.Join(dir, stored.Revision+".json"), data)
+
+	if err != nil {
+		return OMFStoredRevision{}, err
+	}
+
+	index = append(index, stored)
+
+	sort.SliceStable(index, func(i, j int) bool {
+		if index[i].LastUpdated.Equal(index[j].LastUpdated) {
+			return index[i].StoredAt.Before(index[j].StoredAt)
+		}
+
+		return index[i].LastUpdated.Before(index[j].LastUpdated)
+	})
+
+	data, err = json.MarshalIndent(index, "", "  ")
+
+	if err != nil {
+		return OMFStoredRevision{}, err
+	}
+
+	return stored, write_file_atomic(filepath.Join(dir, module_store_index_file), data)
+}
+
+func (s *OMFModuleStore) Ingest(path string, module_name string) (OMFStoredRevision, error) {
+	mod, err := GetOmfCommomStruct(path, module_name, false)
+
+	if err != nil {
+		return OMFStoredRevision{ModuleName: module_name}, err
+	}
+
+	return s.Put(mod)
+}
+
+func (s *OMFModuleStore) History(module_name string) ([]OMFStoredRevision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.read_index(module_name)
+}
+
+func (s *OMFModuleStore) Get(module_name string, revision string) (OMFModule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	index, err := s.read_index(module_name)
+
+	if err != nil {
+		return OMFModule{Name: module_name}, err
+	}
+
+	for _, rev := range index {
+		if rev.Revision != revision {
+			continue
+		}
+
+		dir, _ := s.module_dir(module_name)
+
+		data, err := os.ReadFile(filepath.Join(dir, revision+".json"))
+
+		if err != nil {
+			return OMFModule{Name: module_name}, err
+		}
+
+		var mod OMFModule
+
+		err = json.Unmarshal(data, &mod)
+
+		return mod, err
+	}
+
+	return OMFModule{Name: module_name}, fmt.Errorf("%s revision %s: %w", module_name, revision, ErrModuleNotStored)
+}
+
+func (s *OMFModuleStore) Latest(module_name string) (OMFModule, error) {
+	index, err := s.History(module_name)
+
+	if err != nil {
+		return OMFModule{Name: module_name}, err
+	}
+
+	if len(index) == 0 {
+		return OMFModule{Name: module_name}, fmt.Errorf("%s: %w", module_name, ErrModuleNotStored)
+	}
+
+	return s.Get(module_name, index[len(index)-1].Revision)
+}
+
+func (s *OMFModuleStore) Modules() ([]string, error) {
+	entries, err := os.ReadDir(s.root)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		_, err := os.Stat(filepath.Join(s.root, entry.Name(), module_store_index_file))
+
+		if err == nil {
+			names = append(names, entry.Name())
+		}
+	}
+
+	return names, nil
+}
@@ -0,0 +1,142 @@
+package omifier
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestModuleStoreKeepsChangedContentOfARevision(t *testing.T) {
+	store, err := OpenOmfModuleStore(t.TempDir())
+
+	if err != nil {
+		t.Fatalf("OpenOmfModuleStore: %s", err)
+	}
+
+	stored, err := store.Ingest(omf_testdata_path, "ACME-TEST-MIB")
+
+	if err != nil {
+		t.Fatalf("Ingest: %s", err)
+	}
+
+	if stored.Revision != "202401150000Z" {
+		t.Errorf("stored revision %s, want 202401150000Z", stored.Revision)
+	}
+
+	again, err := store.Ingest(omf_testdata_path, "ACME-TEST-MIB")
+
+	if err != nil || again != stored {
+		t.Errorf("storing the same module again returned %+v (%v), want %+v", again, err, stored)
+	}
+
+	mod, err := store.Get("ACME-TEST-MIB", stored.Revision)
+
+	if err != nil {
+		t.Fatalf("Get: %s", err)
+	}
+
+	changed := mod
+
+	changed.Description = "changed without a new revision"
+
+	changed_stored, err := store.Put(changed)
+
+	if err != nil {
+		t.Fatalf("Put: %s", err)
+	}
+
+	if changed_stored.Revision != stored.Revision+"-"+changed_stored.ContentHash {
+		t.Errorf("changed module stored as %s, want %s-%s", changed_stored.Revision, stored.Revision, changed_stored.ContentHash)
+	}
+
+	history, err := store.History("ACME-TEST-MIB")
+
+	if err != nil || len(history) != 2 {
+		t.Fatalf("History = %+v (%v), want both revisions", history, err)
+	}
+
+	original, err := store.Get("ACME-TEST-MIB", stored.Revision)
+
+	if err != nil || original.Description != mod.Description {
+		t.Errorf("the original revision was overwritten: %q (%v)", original.Description, err)
+	}
+
+	latest, err := store.Latest("ACME-TEST-MIB")
+
+	if err != nil || latest.Description != changed.Description {
+		t.Errorf("Latest = %q (%v), want the changed module", latest.Description, err)
+	}
+
+	again, err = store.Put(changed)
+
+	if err != nil || again.Revision != changed_stored.Revision {
+		t.Errorf("storing the changed module again returned %+v (%v)", again, err)
+	}
+
+	if history, _ := store.History("ACME-TEST-MIB"); len(history) != 2 {
+		t.Errorf("storing the changed module again left %d revisions", len(history))
+	}
+}
+
+func TestModuleStoreKeepsChangedTablesOfARevision(t *testing.T) {
+	store, err := OpenOmfModuleStore(t.TempDir())
+
+	if err != nil {
+		t.Fatalf("OpenOmfModuleStore: %s", err)
+	}
+
+	stored, err := store.Ingest(omf_testdata_path, "ACME-TEST-MIB")
+
+	if err != nil {
+		t.Fatalf("Ingest: %s", err)
+	}
+
+	mod, err := store.Get("ACME-TEST-MIB", stored.Revision)
+
+	if err != nil || len(mod.Tables) == 0 {
+		t.Fatalf("Get returned %d tables (%v)", len(mod.Tables), err)
+	}
+
+	mod.Tables = nil
+
+	changed_stored, err := store.Put(mod)
+
+	if err != nil {
+		t.Fatalf("Put: %s", err)
+	}
+
+	if changed_stored.ModuleHash != stored.ModuleHash || changed_stored.Revision == stored.Revision {
+		t.Errorf("a module without tables was stored as %+v, want a new revision next to %+v", changed_stored, stored)
+	}
+
+	if history, _ := store.History("ACME-TEST-MIB"); len(history) != 2 {
+		t.Errorf("History has %d revisions, want 2", len(history))
+	}
+
+	latest, err := store.Latest("ACME-TEST-MIB")
+
+	if err != nil || len(latest.Tables) != 0 {
+		t.Errorf("Latest has %d tables (%v), want none", len(latest.Tables), err)
+	}
+}
+
+func TestModuleStoreMissingModules(t *testing.T) {
+	store, err := OpenOmfModuleStore(t.TempDir())
+
+	if err != nil {
+		t.Fatalf("OpenOmfModuleStore: %s", err)
+	}
+
+	if _, err := store.Latest("ACME-TEST-MIB"); !errors.Is(err, ErrModuleNotStored) {
+		t.Errorf("Latest of an empty store = %v, want ErrModuleNotStored", err)
+	}
+
+	if _, err := store.Put(OMFModule{Name: "../escape"}); err == nil {
+		t.Errorf("Put accepted a module name that is not an SMI identifier")
+	}
+
+	names, err := store.Modules()
+
+	if err != nil || len(names) != 0 {
+		t.Errorf("Modules of an empty store = %v (%v)", names, err)
+	}
+}
@@ -0,0 +1,319 @@
+package omifier
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/belqlabs/omf-gosmi"
+	"github.com/belqlabs/omf-gosmi/parser"
+)
+
+type OMFParseBackDifference struct {
+	Object   string
+	Field    string
+	Original string
+	Reparsed string
+}
+
+type OMFParseBackReport struct {
+	ModuleName  string
+	Lossless    bool
+	Differences []OMFParseBackDifference `json:",omitempty"`
+}
+
+func normalize_smi_text(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+func (r *OMFParseBackReport) compare(object string, field string, original string, reparsed string) {
+	if original == reparsed {
+		return
+	}
+
+	r.Differences = append(r.Differences, OMFParseBackDifference{
+		Object:   object,
+		Field:    field,
+		Original: original,
+		Reparsed: reparsed,
+	})
+}
+
+func (r *OMFParseBackReport) compare_types(object string, original *OMFType, reparsed *OMFType) {
+	if original == nil {
+		original = &OMFType{}
+	}
+
+	if reparsed == nil {
+		reparsed = &OMFType{}
+	}
+
+	r.compare(object, "Type.Name", original.Name, reparsed.Name)
+
+	r.compare(object, "Type.BaseType", original.BaseType, reparsed.BaseType)
+
+	r.compare(object, "Type.Status", original.Status, reparsed.Status)
+
+	r.compare(object, "Type.Format", original.Format, reparsed.Format)
+
+	r.compare(object, "Type.Units", original.Units, reparsed.Units)
+
+	r.compare(object, "Type.Enum", fmt.Sprintf("%v", original.Enum), fmt.Sprintf("%v", reparsed.Enum))
+
+	r.compare(object, "Type.Ranges", fmt.Sprintf("%v", original.Ranges), fmt.Sprintf("%v", reparsed.Ranges))
+}
+
+func (r *OMFParseBackReport) compare_nodes(original *OMFNode, reparsed *OMFNode) {
+	object := original.Name
+
+	r.compare(object, "Oid", original.Oid, reparsed.Oid)
+
+	r.compare(object, "Kind", original.Kind, reparsed.Kind)
+
+	r.compare(object, "Decl", original.Decl, reparsed.Decl)
+
+	r.compare(object, "Access", original.Access, reparsed.Access)
+
+	r.compare(object, "Status", original.Status, reparsed.Status)
+
+	r.compare(object, "Description", normalize_smi_text(original.Description), normalize_smi_text(reparsed.Description))
+
+	r.compare_types(object, original.Type, reparsed.Type)
+}
+
+func source_object_access(parsed_module *parser.Module) map[string]string {
+	access := make(map[string]string)
+
+	if parsed_module == nil {
+		return access
+	}
+
+	for _, nd := range parsed_module.Body.Nodes {
+		if nd.ObjectType != nil {
+			access[string(nd.Name)] = string(nd.ObjectType.Access)
+		}
+	}
+
+	return access
+}
+
+// gosmi folds read-create into ReadWrite, so the access clauses are also
+// compared as written in the original and the rendered source.
+func (r *OMFParseBackReport) compare_source_access(original *parser.Module, rendered *parser.Module) {
+	if original == nil {
+		return
+	}
+
+	rendered_access := source_object_access(rendered)
+
+	for _, nd := range original.Body.Nodes {
+		if nd.ObjectType == nil {
+			continue
+		}
+
+		if reparsed, found := rendered_access[string(nd.Name)]; found {
+			r.compare(string(nd.Name), "SourceAccess", string(nd.ObjectType.Access), reparsed)
+		}
+	}
+}
+
+func omf_node_names(nds []OMFNode) string {
+	var names []string
+
+	for _, nd := range nds {
+		names = append(names, nd.Name)
+	}
+
+	return strings.Join(names, ", ")
+}
+
+func omf_index_names(idxs []OMFIndex) string {
+	var names []string
+
+	for _, idx := range idxs {
+		names = append(names, idx.Name)
+	}
+
+	return strings.Join(names, ", ")
+}
+
+func compare_omf_modules(original *OMFModule, reparsed *OMFModule) OMFParseBackReport {
+	report := OMFParseBackReport{
+		ModuleName: original.Name,
+	}
+
+	object := original.Name
+
+	report.compare(object, "Organization", normalize_smi_text(original.Organization), normalize_smi_text(reparsed.Organization))
+
+	report.compare(object, "ContactInfo", normalize_smi_text(original.ContactInfo), normalize_smi_text(reparsed.ContactInfo))
+
+	report.compare(object, "Description", normalize_smi_text(original.Description), normalize_smi_text(reparsed.Description))
+
+	report.compare(object, "LastUpdated", original.LastUpdated.String(), reparsed.LastUpdated.String())
+
+	report.compare(object, "IdentityName", original.IdentityName, reparsed.IdentityName)
+
+	report.compare(object, "IdentityOid", original.IdentityOid, reparsed.IdentityOid)
+
+	report.compare(object, "Revisions", fmt.Sprintf("%d", len(original.Revisions)), fmt.Sprintf("%d", len(reparsed.Revisions)))
+
+	reparsed_types := make(map[string]OMFType)
+
+	for _, tp := range reparsed.Types {
+		reparsed_types[tp.Name] = tp
+	}
+
+	for _, tp := range original.Types {
+		reparsed_tp, found := reparsed_types[tp.Name]
+
+		if !found {
+			report.compare(tp.Name, "Presence", "type", "")
+
+			continue
+		}
+
+		report.compare(tp.Name, "Decl", tp.Decl, reparsed_tp.Decl)
+
+		report.compare(tp.Name, "Description", normalize_smi_text(tp.Description), normalize_smi_text(reparsed_tp.Description))
+
+		report.compare_types(tp.Name, &tp, &reparsed_tp)
+	}
+
+	reparsed_nodes := make(map[string]OMFNode)
+
+	for _, nd := range omf_module_nodes(reparsed) {
+		reparsed_nodes[nd.Name] = nd
+	}
+
+	original_nodes := make(map[string]bool)
+
+	for _, nd := range omf_module_nodes(original) {
+		original_nodes[nd.Name] = true
+
+		reparsed_nd, found := reparsed_nodes[nd.Name]
+
+		if !found {
+			report.compare(nd.Name, "Presence", nd.Kind, "")
+
+			continue
+		}
+
+		report.compare_nodes(&nd, &reparsed_nd)
+	}
+
+	for _, nd := range omf_module_nodes(reparsed) {
+		if !original_nodes[nd.Name] {
+			report.compare(nd.Name, "Presence", "", nd.Kind)
+		}
+	}
+
+	reparsed_tables := make(map[string]OMFTable)
+
+	for _, tb := range reparsed.Tables {
+		reparsed_tables[tb.Name] = tb
+	}
+
+	for _, tb := range original.Tables {
+		reparsed_tb := reparsed_tables[tb.Name]
+
+		report.compare(tb.Name, "Indexes", omf_index_names(tb.Indexes), omf_index_names(reparsed_tb.Indexes))
+
+		report.compare(tb.Name, "Columns", omf_node_names(tb.Columns), omf_node_names(reparsed_tb.Columns))
+	}
+
+	reparsed_notifications := make(map[string]OMFNotification)
+
+	for _, nf := range reparsed.Notifications {
+		reparsed_notifications[nf.Name] = nf
+	}
+
+	for _, nf := range original.Notifications {
+		reparsed_nf := reparsed_notifications[nf.Name]
+
+		report.compare(nf.Name, "Objects", omf_node_names(nf.Objects), omf_node_names(reparsed_nf.Objects))
+	}
+
+	reparsed_groups := make(map[string]OMFGroup)
+
+	for _, gr := range reparsed.Groups {
+		reparsed_groups[gr.Name] = gr
+	}
+
+	for _, gr := range original.Groups {
+		reparsed_gr := reparsed_groups[gr.Name]
+
+		report.compare(gr.Name, "Members", omf_node_names(gr.Members), omf_node_names(reparsed_gr.Members))
+	}
+
+	reparsed_compliances := make(map[string]OMFCompliance)
+
+	for _, cp := range reparsed.Compliances {
+		reparsed_compliances[cp.Name] = cp
+	}
+
+	for _, cp := range original.Compliances {
+		reparsed_cp := reparsed_compliances[cp.Name]
+
+		report.compare(cp.Name, "Modules", fmt.Sprintf("%v", cp.Modules), fmt.Sprintf("%v", reparsed_cp.Modules))
+	}
+
+	return report
+}
+
+func parse_back_omf_module(path string, mod *OMFModule) (OMFParseBackReport, error) {
+	source, err := render_smi_module(mod)
+
+	if err != nil {
+		return OMFParseBackReport{}, err
+	}
+
+	rendered_fs, _, err := new_memory_mib_fs(mod.Name, [][]byte{[]byte(source)})
+
+	if err != nil {
+		return OMFParseBackReport{}, err
+	}
+
+	rendered, err := mount_mib_source("omf-parse-back-"+mod.Name, rendered_fs, true)
+
+	if err != nil {
+		return OMFParseBackReport{}, err
+	}
+
+	defer rendered.Close()
+
+	gosmi.Init()
+
+	defer exit_gosmi()
+
+	append_mib_path(path)
+
+	prepend_mib_path(rendered.Path)
+
+	_, err = gosmi.LoadModule(mod.Name)
+
+	if err != nil {
+		return OMFParseBackReport{}, err
+	}
+
+	m, err := gosmi.GetModule(mod.Name)
+
+	if err != nil {
+		return OMFParseBackReport{}, err
+	}
+
+	reparsed := omfy_module(&m)
+
+	report := compare_omf_modules(mod, &reparsed)
+
+	rendered_module, err := parser.Parse(strings.NewReader(source))
+
+	if err != nil {
+		return OMFParseBackReport{}, err
+	}
+
+	report.compare_source_access(read_module_source(mod.Path), rendered_module)
+
+	report.Lossless = len(report.Differences) == 0
+
+	return report, nil
+}
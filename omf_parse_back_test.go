@@ -0,0 +1,39 @@
+package omifier
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseBackReport(t *testing.T) {
+	tests := []struct {
+		module      string
+		lossless    bool
+		differences []OMFParseBackDifference
+	}{
+		{"ACME-TEST-MIB", false, []OMFParseBackDifference{
+			{Object: "acmePortRowStatus", Field: "SourceAccess", Original: "read-create", Reparsed: "read-write"},
+		}},
+		{"GAP-MIB", false, []OMFParseBackDifference{
+			{Object: "GAP-MIB", Field: "IdentityName", Original: "", Reparsed: "gapMIB"},
+			{Object: "GAP-MIB", Field: "IdentityOid", Original: "", Reparsed: "1.3.6.1.4.1.77778.8"},
+			{Object: "gapMIB", Field: "Presence", Original: "", Reparsed: "Node"},
+		}},
+	}
+
+	for _, tt := range tests {
+		mod, err := GetOmfCommomStruct(omf_testdata_path, tt.module, true)
+
+		if err != nil {
+			t.Fatalf("GetOmfCommomStruct(%s): %s", tt.module, err)
+		}
+
+		if mod.ParseBack == nil {
+			t.Fatalf("%s: parseBack produced no report", tt.module)
+		}
+
+		if mod.ParseBack.Lossless != tt.lossless || !slices.Equal(mod.ParseBack.Differences, tt.differences) {
+			t.Errorf("%s: parse back is lossless %v with %+v, want %v with %+v", tt.module, mod.ParseBack.Lossless, mod.ParseBack.Differences, tt.lossless, tt.differences)
+		}
+	}
+}
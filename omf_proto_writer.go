@@ -0,0 +1,376 @@
+package omifier
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var proto_base_types = map[string]string{
+	"Integer32":        "int32",
+	"Integer64":        "int64",
+	"Unsigned32":       "uint32",
+	"Unsigned64":       "uint64",
+	"Counter32":        "uint32",
+	"Counter64":        "uint64",
+	"Gauge32":          "uint32",
+	"TimeTicks":        "uint32",
+	"ObjectIdentifier": "string",
+	"OctetString":      "bytes",
+	"Bits":             "bytes",
+	"IpAddress":        "bytes",
+}
+
+const proto_max_field_number = 536870911
+
+// Indexes imported from other tables have no sub-identifier in the row, so
+// they are numbered from this offset in INDEX order. Notification objects
+// whose sub-identifier is taken by another object use the same offset.
+const proto_external_index_offset = 10000
+
+const proto_options_file = "omf_options.proto"
+
+const proto_options_package = "omf"
+
+const proto_options_source = `syntax = "proto3";
+
+package omf;
+
+import "google/protobuf/descriptor.proto";
+
+extend google.protobuf.MessageOptions {
+  string message_oid = 50001;
+}
+
+extend google.protobuf.FieldOptions {
+  string field_oid = 50001;
+}
+
+extend google.protobuf.EnumOptions {
+  string enum_oid = 50001;
+}
+`
+
+type proto_writer struct {
+	module     *OMFModule
+	enums      strings.Builder
+	body       strings.Builder
+	type_names map[string]bool
+	oid_names  map[string]string
+}
+
+// An acronym run such as the HC of ifHCInOctets stays one word.
+func proto_field_name(name string) string {
+	var field strings.Builder
+
+	runes := []rune(name)
+
+	for idx, r := range runes {
+		if r == '-' {
+			field.WriteRune('_')
+
+			continue
+		}
+
+		if unicode.IsUpper(r) && idx > 0 {
+			prev := runes[idx-1]
+
+			next_lower := idx+1 < len(runes) && unicode.IsLower(runes[idx+1])
+
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && next_lower) {
+				field.WriteRune('_')
+			}
+		}
+
+		field.WriteRune(unicode.ToLower(r))
+	}
+
+	return field.String()
+}
+
+func proto_enum_value_name(type_name string, label string) string {
+	return strings.ToUpper(proto_field_name(type_name) + "_" + proto_field_name(label))
+}
+
+func proto_field_number(oid string) (int, bool) {
+	arcs := split_oid_arcs(oid)
+
+	if len(arcs) == 0 {
+		return 0, false
+	}
+
+	number, err := strconv.Atoi(arcs[len(arcs)-1])
+
+	if err != nil || number < 1 || number > proto_max_field_number || (number >= 19000 && number <= 19999) {
+		return 0, false
+	}
+
+	return number, true
+}
+
+func new_proto_writer(mod *OMFModule) *proto_writer {
+	return &proto_writer{
+		module:     mod,
+		type_names: make(map[string]bool),
+		oid_names:  omf_module_oid_names(mod),
+	}
+}
+
+func (w *proto_writer) printf(format string, args ...any) {
+	fmt.Fprintf(&w.body, format, args...)
+}
+
+func (w *proto_writer) write_enum(type_name string, tp *OMFType, oid string) {
+	fmt.Fprintf(&w.enums, "enum %s {\n", type_name)
+
+	if oid != "" {
+		fmt.Fprintf(&w.enums, "  option (%s.enum_oid) = %q;\n", proto_options_package, oid)
+	}
+
+	labels := smi_ordered_enum(tp.Enum)
+
+	var zero_labels, other_labels []string
+
+	names := make(map[string]bool)
+
+	values := make(map[int64]bool)
+
+	allow_alias := false
+
+	for _, label := range labels {
+		if tp.Enum[label] == 0 {
+			zero_labels = append(zero_labels, label)
+		} else {
+			other_labels = append(other_labels, label)
+		}
+
+		names[proto_enum_value_name(type_name, label)] = true
+
+		allow_alias = allow_alias || values[tp.Enum[label]]
+
+		values[tp.Enum[label]] = true
+	}
+
+	if allow_alias {
+		w.enums.WriteString("  option allow_alias = true;\n")
+	}
+
+	// proto3 needs a first value of 0, which is synthesized when no label
+	// has it.
+	if len(zero_labels) == 0 {
+		unspecified := proto_enum_value_name(type_name, "unspecified")
+
+		for names[unspecified] {
+			unspecified += "_VALUE"
+		}
+
+		fmt.Fprintf(&w.enums, "  %s = 0;\n", unspecified)
+	}
+
+	for _, label := range append(zero_labels, other_labels...) {
+		fmt.Fprintf(&w.enums, "  %s = %d;\n", proto_enum_value_name(type_name, label), tp.Enum[label])
+	}
+
+	w.enums.WriteString("}\n\n")
+}
+
+func (w *proto_writer) field_type(nd *OMFNode) string {
+	tp := nd.Type
+
+	if tp == nil {
+		return "bytes"
+	}
+
+	if tp.BaseType == "Enum" && len(tp.Enum) > 0 {
+		type_name := go_identifier(nd.Name)
+
+		oid := nd.Oid
+
+		if tp.Decl != "ImplicitType" && tp.Name != "" && smi_identifier_regex.MatchString(tp.Name) {
+			type_name = go_identifier(tp.Name)
+
+			oid = ""
+		}
+
+		if !w.type_names[type_name] {
+			w.type_names[type_name] = true
+
+			w.write_enum(type_name, tp, oid)
+		}
+
+		return type_name
+	}
+
+	if tp.BaseType == "OctetString" && (yang_format_string_regex.MatchString(tp.Format) || tp.Name == "DisplayString" || tp.Name == "SnmpAdminString") {
+		return "string"
+	}
+
+	proto_type, ok := proto_base_types[tp.Name]
+
+	if !ok {
+		proto_type, ok = proto_base_types[tp.BaseType]
+	}
+
+	if !ok {
+		return "bytes"
+	}
+
+	return proto_type
+}
+
+func (w *proto_writer) field(nd *OMFNode, number int) string {
+	return fmt.Sprintf("  %s %s = %d [(%s.field_oid) = %q];\n", w.field_type(nd), proto_field_name(nd.Name), number, proto_options_package, nd.Oid)
+}
+
+func (w *proto_writer) oid_field(nd *OMFNode) string {
+	number, ok := proto_field_number(nd.Oid)
+
+	if !ok {
+		return fmt.Sprintf("  // %s: sub-identifier of %s is not a valid field number\n", nd.Name, nd.Oid)
+	}
+
+	return w.field(nd, number)
+}
+
+func (w *proto_writer) write_message(name string, oid string, description string, fields string) {
+	if description != "" {
+		w.printf("// %s\n", normalize_smi_text(description))
+	}
+
+	w.printf("message %s {\n  option (%s.message_oid) = %q;\n\n%s}\n\n", name, proto_options_package, oid, fields)
+}
+
+func (w *proto_writer) write_scalars() {
+	var groups []string
+
+	group_fields := make(map[string]string)
+
+	group_oids := make(map[string]string)
+
+	for _, sc := range w.module.Scalars {
+		group, group_oid := omf_scalar_parent(w.oid_names, &sc.OMFNode)
+
+		if _, ok := group_fields[group]; !ok {
+			groups = append(groups, group)
+
+			group_oids[group] = group_oid
+		}
+
+		group_fields[group] += w.oid_field(&sc.OMFNode)
+	}
+
+	for _, group := range groups {
+		w.write_message(go_identifier(group), group_oids[group], "", group_fields[group])
+	}
+}
+
+func (w *proto_writer) write_table(tb *OMFTable) {
+	if !smi_identifier_regex.MatchString(tb.Entry.Name) {
+		return
+	}
+
+	var fields string
+
+	columns := make(map[string]bool)
+
+	for _, col := range tb.Columns {
+		columns[col.Name] = true
+	}
+
+	for idx, index := range tb.Indexes {
+		if !columns[index.Name] {
+			fields += w.field(&index.OMFNode, proto_external_index_offset+idx)
+		}
+	}
+
+	for _, col := range tb.Columns {
+		fields += w.oid_field(&col)
+	}
+
+	w.write_message(go_identifier(tb.Entry.Name), tb.Entry.Oid, tb.Entry.Description, fields)
+}
+
+func (w *proto_writer) write_notification(nf *OMFNotification) {
+	var fields string
+
+	numbers := make(map[int]bool)
+
+	for idx, obj := range nf.Objects {
+		number, ok := proto_field_number(obj.Oid)
+
+		if !ok || numbers[number] {
+			number = proto_external_index_offset + idx
+		}
+
+		numbers[number] = true
+
+		fields += w.field(&obj, number)
+	}
+
+	w.write_message(go_identifier(nf.Name), nf.Oid, nf.Description, fields)
+}
+
+func render_proto_source(mod *OMFModule, package_name string) string {
+	w := new_proto_writer(mod)
+
+	w.write_scalars()
+
+	for _, tb := range mod.Tables {
+		w.write_table(&tb)
+	}
+
+	for _, nf := range mod.Notifications {
+		w.write_notification(&nf)
+	}
+
+	var out strings.Builder
+
+	fmt.Fprintf(&out, "// Code generated by omifier from %s. DO NOT EDIT.\n\n", mod.Name)
+
+	out.WriteString("syntax = \"proto3\";\n\n")
+
+	fmt.Fprintf(&out, "package %s;\n\n", package_name)
+
+	fmt.Fprintf(&out, "import %q;\n\n", proto_options_file)
+
+	out.WriteString(w.enums.String())
+
+	out.WriteString(strings.TrimSuffix(w.body.String(), "\n"))
+
+	return out.String()
+}
+
+func GenerateProtoSource(mod OMFModule, package_name string) (string, error) {
+	if mod.Name == "" {
+		return "", errors.New("cannot generate a proto schema for an unnamed module")
+	}
+
+	if package_name == "" {
+		package_name = go_package_name(mod.Name)
+	}
+
+	return render_proto_source(&mod, package_name), nil
+}
+
+// Writes omf_options.proto, which declares the OID options and is imported
+// by every generated schema. It belongs next to the schemas, once.
+func WriteProtoOptions(out io.Writer) error {
+	_, err := io.WriteString(out, proto_options_source)
+
+	return err
+}
+
+func WriteProtoSource(out io.Writer, mod OMFModule, package_name string) error {
+	source, err := GenerateProtoSource(mod, package_name)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(out, source)
+
+	return err
+}
@@ -0,0 +1,140 @@
+package omifier
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestGenerateProtoSource(t *testing.T) {
+	mod, err := GetOmfCommomStruct(omf_testdata_path, "ACME-TEST-MIB", false)
+
+	if err != nil {
+		t.Fatalf("GetOmfCommomStruct: %s", err)
+	}
+
+	source, err := GenerateProtoSource(mod, "")
+
+	if err != nil {
+		t.Fatalf("GenerateProtoSource: %s", err)
+	}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"package", "package acmetestmib;\n"},
+		{"options import", "import \"omf_options.proto\";\n"},
+		{"message option", "option (omf.message_oid) = \"1.3.6.1.4.1.99999.1.10.1\";"},
+		{"column from arc", "uint32 acme_port_in_octets = 5 [(omf.field_oid) = \"1.3.6.1.4.1.99999.1.10.1.5\"];"},
+		{"notification object from arc", "message AcmePortDown {\n  option (omf.message_oid) = \"1.3.6.1.4.1.99999.2.1\";\n\n  string acme_port_name = 2 "},
+		{"second notification object from arc", "AcmePortState acme_port_state = 4 [(omf.field_oid) = \"1.3.6.1.4.1.99999.1.10.1.4\"];\n}"},
+	}
+
+	for _, tt := range tests {
+		if !strings.Contains(source, tt.want) {
+			t.Errorf("%s: proto schema has no %q", tt.name, tt.want)
+		}
+	}
+
+	if strings.Contains(source, "extend ") {
+		t.Errorf("proto schema declares its own extensions")
+	}
+
+	var options bytes.Buffer
+
+	err = WriteProtoOptions(&options)
+
+	if err != nil {
+		t.Fatalf("WriteProtoOptions: %s", err)
+	}
+
+	for _, option := range []string{"message_oid", "field_oid", "enum_oid"} {
+		if !strings.Contains(options.String(), "string "+option+" = 50001;") {
+			t.Errorf("omf_options.proto does not declare %s", option)
+		}
+	}
+
+	if !strings.Contains(options.String(), "package omf;") {
+		t.Errorf("omf_options.proto is not in package omf")
+	}
+}
+
+func TestProtoNotificationNumbersAvoidCollisions(t *testing.T) {
+	mod := OMFModule{
+		Name: "TRAP-MIB",
+		Notifications: []OMFNotification{{
+			OMFNode: OMFNode{Name: "linkFlap", Oid: "1.3.6.1.4.1.77784.0.1"},
+			Objects: []OMFNode{
+				{Name: "ifName", Oid: "1.3.6.1.4.1.77784.1.1.1.2", Type: &OMFType{Name: "DisplayString", BaseType: "OctetString"}},
+				{Name: "peerName", Oid: "1.3.6.1.4.1.77784.2.1.1.2", Type: &OMFType{Name: "DisplayString", BaseType: "OctetString"}},
+			},
+		}},
+	}
+
+	source, err := GenerateProtoSource(mod, "")
+
+	if err != nil {
+		t.Fatalf("GenerateProtoSource: %s", err)
+	}
+
+	for _, want := range []string{"string if_name = 2 ", "string peer_name = 10001 "} {
+		if !strings.Contains(source, want) {
+			t.Errorf("proto schema has no %q:\n%s", want, source)
+		}
+	}
+}
+
+func TestProtoFieldName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"ifHCInOctets", "if_hc_in_octets"},
+		{"acmePortInOctets", "acme_port_in_octets"},
+		{"ipv6IfIndex", "ipv6_if_index"},
+		{"AcmePortState", "acme_port_state"},
+		{"not-in-service", "not_in_service"},
+		{"sysORID", "sys_orid"},
+	}
+
+	for _, tt := range tests {
+		if got := proto_field_name(tt.name); got != tt.want {
+			t.Errorf("proto_field_name(%s) = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestProtoEnumZeroValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		enum     OMFEnum
+		contains []string
+		excludes []string
+	}{
+		{"zero label", OMFEnum{"unknown": 0, "up": 1}, []string{"  PROTO_STATE_UNKNOWN = 0;\n", "  PROTO_STATE_UP = 1;\n"}, []string{"UNSPECIFIED", "allow_alias"}},
+		{"no zero label", OMFEnum{"up": 1, "down": 2}, []string{"{\n  PROTO_STATE_UNSPECIFIED = 0;\n  PROTO_STATE_UP = 1;\n"}, []string{"allow_alias"}},
+		{"unspecified label", OMFEnum{"unspecified": 3}, []string{"  PROTO_STATE_UNSPECIFIED_VALUE = 0;\n", "  PROTO_STATE_UNSPECIFIED = 3;\n"}, []string{"allow_alias"}},
+		{"aliased values", OMFEnum{"off": 0, "disabled": 0, "on": 1}, []string{"  option allow_alias = true;\n"}, []string{"UNSPECIFIED"}},
+	}
+
+	for _, tt := range tests {
+		w := new_proto_writer(&OMFModule{Name: "PROTO-MIB"})
+
+		w.write_enum("ProtoState", &OMFType{BaseType: "Enum", Enum: tt.enum}, "")
+
+		enum := w.enums.String()
+
+		for _, want := range tt.contains {
+			if !strings.Contains(enum, want) {
+				t.Errorf("%s: enum has no %q:\n%s", tt.name, want, enum)
+			}
+		}
+
+		for _, unwanted := range tt.excludes {
+			if strings.Contains(enum, unwanted) {
+				t.Errorf("%s: enum has %q:\n%s", tt.name, unwanted, enum)
+			}
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package omifier
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	gosmi "github.com/belqlabs/omf-gosmi"
+)
+
+type OMFRegistryTree struct {
+	Paths          []string
+	Modules        []string
+	ConflictPolicy OMFConflictPolicy `json:",omitempty"`
+	Conflicts      []OMFTreeConflict `json:",omitempty"`
+	Tree           OMFTreeChildren
+	loaded         map[string]registry_module
+	dependencies   map[string][]string
+}
+
+// What the tree needs of a loaded module, kept so the tree can be rebuilt
+// without loading the module again.
+type registry_module struct {
+	last_updated  time.Time
+	nodes         []OMFNode
+	imported_oids []string
+}
+
+func NewOmfRegistryTree(policy OMFConflictPolicy, paths ...string) *OMFRegistryTree {
+	if policy == "" {
+		policy = OMFConflictKeepFirst
+	}
+
+	return &OMFRegistryTree{
+		Paths:          paths,
+		ConflictPolicy: policy,
+		Tree:           make(OMFTreeChildren),
+	}
+}
+
+func read_registry_module(module *gosmi.SmiModule) registry_module {
+	loaded := registry_module{last_updated: module_last_updated(module)}
+
+	for _, node := range module.GetNodes() {
+		loaded.nodes = append(loaded.nodes, omfy_node(&node))
+	}
+
+	for _, imp := range module.GetImports() {
+		imported_module, err := gosmi.GetModule(imp.Module)
+
+		if err != nil {
+			continue
+		}
+
+		imported_node, err := imported_module.GetNode(imp.Name)
+
+		if err != nil {
+			continue
+		}
+
+		loaded.imported_oids = append(loaded.imported_oids, imported_node.Oid.String())
+	}
+
+	return loaded
+}
+
+// Loads module_name with its imports and returns every module it pulled in,
+// in load order.
+func load_registry_modules(paths []string, module_name string) (map[string]registry_module, []string, error) {
+	gosmi.Init()
+
+	defer exit_gosmi()
+
+	for _, path := range paths {
+		append_mib_path(path)
+	}
+
+	err := append_module(module_name)
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("loading %s: %w", module_name, err)
+	}
+
+	err = unresolved_imports_error(load_module_imports([]string{module_name}))
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	loaded := make(map[string]registry_module)
+
+	var dependencies []string
+
+	for _, module := range gosmi.GetLoadedModules() {
+		loaded[module.Name] = read_registry_module(&module)
+
+		dependencies = append(dependencies, module.Name)
+	}
+
+	return loaded, dependencies, nil
+}
+
+// The tree is rebuilt from every registered module on each change, so the
+// dependencies a removed module pulled in leave the tree with it.
+func (r *OMFRegistryTree) rebuild(modules []string, loaded map[string]registry_module, dependencies map[string][]string) error {
+	var module_names []string
+
+	for _, module_name := range modules {
+		for _, dependency := range dependencies[module_name] {
+			if !slices.Contains(module_names, dependency) {
+				module_names = append(module_names, dependency)
+			}
+		}
+	}
+
+	children := make(OMFTreeChildren)
+
+	var conflicts []OMFTreeConflict
+
+	placer := new_tree_placer(children, r.ConflictPolicy, &conflicts)
+
+	placer.provenance = true
+
+	for _, module_name := range module_names {
+		placer.revisions[module_name] = loaded[module_name].last_updated
+	}
+
+	for _, module_name := range module_names {
+		for _, node := range loaded[module_name].nodes {
+			err := placer.place(node)
+
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, module_name := range module_names {
+		for _, imported_oid := range loaded[module_name].imported_oids {
+			leaf := find_tree_node(imported_oid, children)
+
+			if leaf != nil && !slices.Contains(leaf.ImportedBy, module_name) {
+				leaf.ImportedBy = append(leaf.ImportedBy, module_name)
+			}
+		}
+	}
+
+	for name := range loaded {
+		if !slices.Contains(module_names, name) {
+			delete(loaded, name)
+		}
+	}
+
+	r.Modules = modules
+
+	r.Conflicts = conflicts
+
+	r.Tree = children
+
+	r.loaded = loaded
+
+	r.dependencies = dependencies
+
+	return nil
+}
+
+func (r *OMFRegistryTree) AddModule(module_name string) error {
+	if slices.Contains(r.Modules, module_name) {
+		return nil
+	}
+
+	added, module_dependencies, err := load_registry_modules(r.Paths, module_name)
+
+	if err != nil {
+		return err
+	}
+
+	loaded := maps.Clone(r.loaded)
+
+	if loaded == nil {
+		loaded = make(map[string]registry_module)
+	}
+
+	maps.Copy(loaded, added)
+
+	dependencies := maps.Clone(r.dependencies)
+
+	if dependencies == nil {
+		dependencies = make(map[string][]string)
+	}
+
+	dependencies[module_name] = module_dependencies
+
+	return r.rebuild(append(slices.Clone(r.Modules), module_name), loaded, dependencies)
+}
+
+func (r *OMFRegistryTree) RemoveModule(module_name string) error {
+	idx := slices.Index(r.Modules, module_name)
+
+	if idx < 0 {
+		return fmt.Errorf("module %s is not registered", module_name)
+	}
+
+	dependencies := maps.Clone(r.dependencies)
+
+	delete(dependencies, module_name)
+
+	return r.rebuild(slices.Delete(slices.Clone(r.Modules), idx, idx+1), maps.Clone(r.loaded), dependencies)
+}
+
+func (r OMFRegistryTree) Find(oid string) *OMFTreeNode {
+	return find_tree_node(oid, r.Tree)
+}
+
+func (r OMFRegistryTree) Walk(visitor OMFTreeVisitor) error {
+	return finish_walk(walk_tree_children(r.Tree, 0, &visitor))
+}
+
+func (r OMFRegistryTree) WalkPrefix(oid string, visitor OMFTreeVisitor) error {
+	return walk_tree_children_prefix(oid, r.Tree, &visitor)
+}
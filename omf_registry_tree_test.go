@@ -0,0 +1,102 @@
+package omifier
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRegistryTreeProvenance(t *testing.T) {
+	registry := NewOmfRegistryTree(OMFConflictKeepFirst, omf_testdata_path)
+
+	for _, module_name := range []string{"ACME-TEST-MIB", "CCITT-MIB"} {
+		err := registry.AddModule(module_name)
+
+		if err != nil {
+			t.Fatalf("AddModule(%s): %s", module_name, err)
+		}
+	}
+
+	enterprises := registry.Find("1.3.6.1.4.1")
+
+	if enterprises == nil || !slices.Equal(enterprises.DefinedBy, []string{"SNMPv2-SMI"}) {
+		t.Fatalf("enterprises is %+v", enterprises)
+	}
+
+	for _, module_name := range []string{"ACME-TEST-MIB", "CCITT-MIB"} {
+		if !slices.Contains(enterprises.ImportedBy, module_name) {
+			t.Errorf("enterprises is not imported by %s: %v", module_name, enterprises.ImportedBy)
+		}
+	}
+
+	leaf := registry.Find("1.3.6.1.4.1.77779.1")
+
+	if leaf == nil || leaf.Node.Name != "ccittEnterpriseLeaf" || !slices.Equal(leaf.DefinedBy, []string{"CCITT-MIB"}) {
+		t.Errorf("ccittEnterpriseLeaf is %+v", leaf)
+	}
+}
+
+func TestRegistryTreeRemoveModule(t *testing.T) {
+	registry := NewOmfRegistryTree(OMFConflictKeepFirst, omf_testdata_path)
+
+	for _, module_name := range []string{"CCITT-MIB", "ACME-TEST-MIB"} {
+		err := registry.AddModule(module_name)
+
+		if err != nil {
+			t.Fatalf("AddModule(%s): %s", module_name, err)
+		}
+	}
+
+	err := registry.RemoveModule("ACME-TEST-MIB")
+
+	if err != nil {
+		t.Fatalf("RemoveModule: %s", err)
+	}
+
+	if registry.Find("1.3.6.1.4.1.99999") != nil {
+		t.Errorf("acmeTestMIB is still in the tree after its module was removed")
+	}
+
+	enterprises := registry.Find("1.3.6.1.4.1")
+
+	if enterprises == nil || slices.Contains(enterprises.ImportedBy, "ACME-TEST-MIB") {
+		t.Errorf("enterprises is %+v after ACME-TEST-MIB was removed", enterprises)
+	}
+
+	if registry.Find("1.3.6.1.4.1.77779.2") == nil {
+		t.Errorf("CCITT-MIB left the tree with ACME-TEST-MIB")
+	}
+
+	err = registry.RemoveModule("CCITT-MIB")
+
+	if err != nil {
+		t.Fatalf("RemoveModule: %s", err)
+	}
+
+	if len(registry.Modules) != 0 || len(registry.Tree) != 0 {
+		t.Errorf("removing every module left %v and a tree of %d arcs", registry.Modules, len(registry.Tree))
+	}
+
+	if err := registry.RemoveModule("CCITT-MIB"); err == nil {
+		t.Errorf("RemoveModule of an unregistered module succeeded")
+	}
+}
+
+func TestRegistryTreeAddMissingModule(t *testing.T) {
+	registry := NewOmfRegistryTree(OMFConflictKeepFirst, omf_testdata_path)
+
+	err := registry.AddModule("ACME-TEST-MIB")
+
+	if err != nil {
+		t.Fatalf("AddModule: %s", err)
+	}
+
+	err = registry.AddModule("NO-SUCH-MIB")
+
+	if err == nil {
+		t.Fatalf("AddModule of a missing module succeeded")
+	}
+
+	if !slices.Equal(registry.Modules, []string{"ACME-TEST-MIB"}) || registry.Find("1.3.6.1.4.1.99999.1.2") == nil {
+		t.Errorf("a failed AddModule changed the registry: %v", registry.Modules)
+	}
+}
@@ -0,0 +1,808 @@
+package omifier
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var smi_identifier_regex = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9-]*$`)
+
+var smi_base_type_modules = map[string]string{
+	"Integer32":  "SNMPv2-SMI",
+	"Unsigned32": "SNMPv2-SMI",
+	"Counter32":  "SNMPv2-SMI",
+	"Counter64":  "SNMPv2-SMI",
+	"Gauge32":    "SNMPv2-SMI",
+	"TimeTicks":  "SNMPv2-SMI",
+	"IpAddress":  "SNMPv2-SMI",
+	"Opaque":     "SNMPv2-SMI",
+}
+
+var smi_macro_modules = map[string]string{
+	"MODULE-IDENTITY":    "SNMPv2-SMI",
+	"OBJECT-IDENTITY":    "SNMPv2-SMI",
+	"OBJECT-TYPE":        "SNMPv2-SMI",
+	"NOTIFICATION-TYPE":  "SNMPv2-SMI",
+	"TEXTUAL-CONVENTION": "SNMPv2-TC",
+	"OBJECT-GROUP":       "SNMPv2-CONF",
+	"NOTIFICATION-GROUP": "SNMPv2-CONF",
+	"MODULE-COMPLIANCE":  "SNMPv2-CONF",
+}
+
+var smi_v1_import_modules = map[string]string{
+	"RFC1155-SMI": "SNMPv2-SMI",
+	"RFC1065-SMI": "SNMPv2-SMI",
+	"RFC-1212":    "",
+	"RFC-1215":    "",
+}
+
+var smi_v1_import_names = map[string]string{
+	"Counter": "Counter32",
+	"Gauge":   "Gauge32",
+}
+
+var smi_unrefinable_types = map[string]bool{
+	"Counter32": true,
+	"Counter64": true,
+	"TimeTicks": true,
+	"IpAddress": true,
+	"Opaque":    true,
+}
+
+var smi_full_ranges = map[string]OMFRange{
+	"Integer32":  {-2147483648, 2147483647},
+	"Unsigned32": {0, 4294967295},
+	"Unsigned64": {0, -1},
+}
+
+// Ranges of the imported textual conventions, a node using one of these
+// unrefined carries the convention's own range.
+var smi_tc_ranges = map[string][]OMFRange{
+	"DisplayString":           {{0, 255}},
+	"MacAddress":              {{6, 6}},
+	"DateAndTime":             {{8, 8}, {11, 11}},
+	"TAddress":                {{1, 255}},
+	"SnmpAdminString":         {{0, 255}},
+	"InetAddress":             {{0, 255}},
+	"InetAddressPrefixLength": {{0, 2040}},
+	"InetPortNumber":          {{0, 65535}},
+}
+
+type smi_writer struct {
+	module        *OMFModule
+	body          strings.Builder
+	required      map[string]map[string]bool
+	oid_names     map[string]string
+	identity_name string
+	identity_oid  string
+	synthesized   bool
+	err           error
+}
+
+func smi_status(status string) string {
+	switch status {
+	case "Deprecated":
+		return "deprecated"
+	case "Obsolete", "Optional":
+		return "obsolete"
+	}
+
+	return "current"
+}
+
+func smi_access(access string) string {
+	switch access {
+	case "NotAccessible":
+		return "not-accessible"
+	case "Notify":
+		return "accessible-for-notify"
+	case "ReadWrite":
+		return "read-write"
+	}
+
+	return "read-only"
+}
+
+// SMI strings have no escape for a double quote (RFC 2578 section 3.1.1), so
+// any inside text become single quotes.
+func smi_quote(text string) string {
+	return "\"" + strings.ReplaceAll(text, "\"", "'") + "\""
+}
+
+func smi_entry_type_name(entry_name string) string {
+	if entry_name == "" {
+		return ""
+	}
+
+	return strings.ToUpper(entry_name[:1]) + entry_name[1:]
+}
+
+func smi_ordered_enum(en OMFEnum) []string {
+	names := make([]string, 0, len(en))
+
+	for name := range en {
+		names = append(names, name)
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		if en[names[i]] == en[names[j]] {
+			return names[i] < names[j]
+		}
+
+		return en[names[i]] < en[names[j]]
+	})
+
+	return names
+}
+
+func smi_range_value(rg OMFRange) string {
+	if rg[0] == rg[1] {
+		return fmt.Sprintf("%d", rg[0])
+	}
+
+	if rg[1] == -1 {
+		return fmt.Sprintf("%d..18446744073709551615", rg[0])
+	}
+
+	return fmt.Sprintf("%d..%d", rg[0], rg[1])
+}
+
+func new_smi_writer(mod *OMFModule) *smi_writer {
+	w := &smi_writer{
+		module:    mod,
+		required:  make(map[string]map[string]bool),
+		oid_names: make(map[string]string),
+	}
+
+	for _, nd := range omf_module_nodes(mod) {
+		if smi_identifier_regex.MatchString(nd.Name) && nd.Oid != "" {
+			w.oid_names[nd.Oid] = nd.Name
+		}
+	}
+
+	w.identity_name, w.identity_oid = mod.IdentityName, mod.IdentityOid
+
+	if w.identity_name == "" || w.identity_oid == "" {
+		w.identity_name, w.identity_oid = w.synthesize_module_identity()
+
+		w.synthesized = true
+	}
+
+	if w.identity_name != "" && w.identity_oid != "" {
+		w.oid_names[w.identity_oid] = w.identity_name
+	}
+
+	return w
+}
+
+func smi_identity_name(module_name string) string {
+	parts := strings.Split(module_name, "-")
+
+	parts[0] = strings.ToLower(parts[0])
+
+	return strings.Join(parts, "")
+}
+
+// SMIv2 requires a MODULE-IDENTITY, which SMIv1 modules never have. One is
+// placed on the arc after the highest one used under the module's common
+// root, which is moved up when it is an object rather than a plain node, and
+// skips any arc a node is already registered on or below.
+func (w *smi_writer) synthesize_module_identity() (string, string) {
+	var root []string
+
+	var oids [][]string
+
+	taken_names := make(map[string]bool)
+
+	taken_oids := make(map[string]bool)
+
+	kinds := make(map[string]string)
+
+	for _, nd := range omf_module_nodes(w.module) {
+		taken_names[nd.Name] = true
+
+		kinds[nd.Oid] = nd.Kind
+
+		arcs := split_oid_arcs(nd.Oid)
+
+		if len(arcs) == 0 || arcs[0] == "0" {
+			continue
+		}
+
+		for idx := range arcs {
+			taken_oids[strings.Join(arcs[:idx+1], ".")] = true
+		}
+
+		oids = append(oids, arcs)
+
+		if root == nil {
+			root = arcs
+
+			continue
+		}
+
+		common_len := 0
+
+		for common_len < len(root) && common_len < len(arcs) && root[common_len] == arcs[common_len] {
+			common_len++
+		}
+
+		root = root[:common_len]
+	}
+
+	for _, imp := range w.module.Imports {
+		for _, imported := range imp.ImportedNodes {
+			taken_names[imported] = true
+		}
+	}
+
+	for len(root) > 1 && kinds[strings.Join(root, ".")] != "" && kinds[strings.Join(root, ".")] != "Node" {
+		root = root[:len(root)-1]
+	}
+
+	if len(root) == 0 {
+		return "", ""
+	}
+
+	next_arc := uint64(1)
+
+	for _, arcs := range oids {
+		if len(arcs) <= len(root) {
+			continue
+		}
+
+		arc, err := strconv.ParseUint(arcs[len(root)], 10, 32)
+
+		if err == nil && arc >= next_arc {
+			next_arc = arc + 1
+		}
+	}
+
+	oid := strings.Join(root, ".") + "." + strconv.FormatUint(next_arc, 10)
+
+	for taken_oids[oid] {
+		next_arc++
+
+		oid = strings.Join(root, ".") + "." + strconv.FormatUint(next_arc, 10)
+	}
+
+	name := smi_identity_name(w.module.Name)
+
+	for taken_names[name] {
+		name += "Identity"
+	}
+
+	return name, oid
+}
+
+func (w *smi_writer) fail(err error) {
+	if w.err == nil {
+		w.err = err
+	}
+}
+
+func (w *smi_writer) require(module string, name string) {
+	if module == "" || module == w.module.Name {
+		return
+	}
+
+	if w.required[module] == nil {
+		w.required[module] = make(map[string]bool)
+	}
+
+	w.required[module][name] = true
+}
+
+func (w *smi_writer) require_macro(macro string) {
+	w.require(smi_macro_modules[macro], macro)
+}
+
+func (w *smi_writer) printf(format string, args ...any) {
+	fmt.Fprintf(&w.body, format, args...)
+}
+
+func (w *smi_writer) oid_reference(oid string) string {
+	arcs := split_oid_arcs(oid)
+
+	for prefix_len := len(arcs) - 1; prefix_len > 0; prefix_len-- {
+		prefix := strings.Join(arcs[:prefix_len], ".")
+
+		if name, ok := w.oid_names[prefix]; ok {
+			return fmt.Sprintf("{ %s %s }", name, strings.Join(arcs[prefix_len:], " "))
+		}
+
+		if well_known, ok := omf_well_known_nodes[prefix]; ok && well_known.Module == "SNMPv2-SMI" {
+			w.require(well_known.Module, well_known.Name)
+
+			return fmt.Sprintf("{ %s %s }", well_known.Name, strings.Join(arcs[prefix_len:], " "))
+		}
+	}
+
+	if len(arcs) > 1 {
+		if well_known, ok := omf_well_known_nodes[arcs[0]]; ok {
+			return fmt.Sprintf("{ %s %s }", well_known.Name, strings.Join(arcs[1:], " "))
+		}
+	}
+
+	return fmt.Sprintf("{ %s }", strings.Join(arcs, " "))
+}
+
+func (w *smi_writer) base_syntax(tp *OMFType) string {
+	name := tp.Name
+
+	switch name {
+	case "Enumeration", "Enum":
+		return "INTEGER"
+	case "Bits":
+		return "BITS"
+	case "OctetString":
+		return "OCTET STRING"
+	case "ObjectIdentifier":
+		return "OBJECT IDENTIFIER"
+	case "Integer64":
+		w.fail(errors.New("Integer64 has no SMIv2 syntax"))
+
+		return name
+	case "":
+		return w.base_syntax(&OMFType{Name: tp.BaseType})
+	}
+
+	if module, ok := smi_base_type_modules[name]; ok {
+		w.require(module, name)
+	}
+
+	return name
+}
+
+func (w *smi_writer) type_refinement(tp *OMFType) string {
+	if tp.BaseType == "Enum" || tp.BaseType == "Bits" {
+		if len(tp.Enum) == 0 || tp.Decl != "ImplicitType" {
+			return ""
+		}
+
+		var values []string
+
+		for _, name := range smi_ordered_enum(tp.Enum) {
+			values = append(values, fmt.Sprintf("%s(%d)", name, tp.Enum[name]))
+		}
+
+		return " { " + strings.Join(values, ", ") + " }"
+	}
+
+	if len(tp.Ranges) == 0 || smi_unrefinable_types[tp.Name] {
+		return ""
+	}
+
+	for _, module_tp := range w.module.Types {
+		if module_tp.Name == tp.Name && fmt.Sprintf("%v", module_tp.Ranges) == fmt.Sprintf("%v", tp.Ranges) {
+			return ""
+		}
+	}
+
+	if tc_ranges, ok := smi_tc_ranges[tp.Name]; ok && fmt.Sprintf("%v", tc_ranges) == fmt.Sprintf("%v", tp.Ranges) {
+		return ""
+	}
+
+	if full_range, ok := smi_full_ranges[tp.BaseType]; ok && len(tp.Ranges) == 1 && tp.Ranges[0] == full_range {
+		return ""
+	}
+
+	var values []string
+
+	for _, rg := range tp.Ranges {
+		values = append(values, smi_range_value(rg))
+	}
+
+	if tp.BaseType == "OctetString" {
+		return " (SIZE (" + strings.Join(values, " | ") + "))"
+	}
+
+	return " (" + strings.Join(values, " | ") + ")"
+}
+
+func (w *smi_writer) type_syntax(tp *OMFType) string {
+	if tp == nil {
+		return "OCTET STRING"
+	}
+
+	return w.base_syntax(tp) + w.type_refinement(tp)
+}
+
+func (w *smi_writer) tc_syntax(tp *OMFType) string {
+	base := OMFType{
+		BaseType: tp.BaseType,
+		Decl:     "ImplicitType",
+		Enum:     tp.Enum,
+		Ranges:   tp.Ranges,
+	}
+
+	return w.type_syntax(&base)
+}
+
+func (w *smi_writer) write_description(keyword string, text string) {
+	w.printf("    %s\n        %s\n", keyword, smi_quote(text))
+}
+
+func (w *smi_writer) write_module_identity() {
+	mod := w.module
+
+	if w.identity_name == "" || w.identity_oid == "" {
+		return
+	}
+
+	w.require_macro("MODULE-IDENTITY")
+
+	if w.synthesized {
+		w.printf("-- %s has no MODULE-IDENTITY, this one is synthesized.\n", mod.Name)
+	}
+
+	w.printf("%s MODULE-IDENTITY\n", w.identity_name)
+
+	w.printf("    LAST-UPDATED %s\n", smi_quote(mod.LastUpdated.UTC().Format("200601021504Z")))
+
+	w.printf("    ORGANIZATION %s\n", smi_quote(mod.Organization))
+
+	w.write_description("CONTACT-INFO", mod.ContactInfo)
+
+	w.write_description("DESCRIPTION", mod.Description)
+
+	revisions := append([]OMFRevision{}, mod.Revisions...)
+
+	sort.SliceStable(revisions, func(i, j int) bool {
+		return revisions[i].Date.After(revisions[j].Date)
+	})
+
+	for _, rev := range revisions {
+		w.printf("    REVISION %s\n", smi_quote(rev.Date.UTC().Format("200601021504Z")))
+
+		w.write_description("DESCRIPTION", rev.Description)
+	}
+
+	w.printf("    ::= %s\n\n", w.oid_reference(w.identity_oid))
+}
+
+func (w *smi_writer) write_type(tp *OMFType) {
+	if !smi_identifier_regex.MatchString(tp.Name) {
+		return
+	}
+
+	if tp.Decl != "TextualConvention" {
+		w.printf("%s ::= %s\n\n", tp.Name, w.tc_syntax(tp))
+
+		return
+	}
+
+	w.require_macro("TEXTUAL-CONVENTION")
+
+	w.printf("%s ::= TEXTUAL-CONVENTION\n", tp.Name)
+
+	if tp.Format != "" {
+		w.printf("    DISPLAY-HINT %s\n", smi_quote(tp.Format))
+	}
+
+	w.printf("    STATUS %s\n", smi_status(tp.Status))
+
+	w.write_description("DESCRIPTION", tp.Description)
+
+	if tp.Reference != "" {
+		w.write_description("REFERENCE", tp.Reference)
+	}
+
+	w.printf("    SYNTAX %s\n\n", w.tc_syntax(tp))
+}
+
+func (w *smi_writer) write_object_identifier(nd *OMFNode) {
+	w.printf("%s OBJECT IDENTIFIER ::= %s\n\n", nd.Name, w.oid_reference(nd.Oid))
+}
+
+func (w *smi_writer) write_object_identity(nd *OMFNode) {
+	w.require_macro("OBJECT-IDENTITY")
+
+	w.printf("%s OBJECT-IDENTITY\n", nd.Name)
+
+	w.printf("    STATUS %s\n", smi_status(nd.Status))
+
+	w.write_description("DESCRIPTION", nd.Description)
+
+	w.printf("    ::= %s\n\n", w.oid_reference(nd.Oid))
+}
+
+func (w *smi_writer) write_object_type(nd *OMFNode, syntax string, indexes []OMFIndex) {
+	w.require_macro("OBJECT-TYPE")
+
+	w.printf("%s OBJECT-TYPE\n", nd.Name)
+
+	w.printf("    SYNTAX %s\n", syntax)
+
+	if nd.Type != nil && nd.Type.Units != "" {
+		w.printf("    UNITS %s\n", smi_quote(nd.Type.Units))
+	}
+
+	w.printf("    MAX-ACCESS %s\n", smi_access(nd.Access))
+
+	w.printf("    STATUS %s\n", smi_status(nd.Status))
+
+	w.write_description("DESCRIPTION", nd.Description)
+
+	if len(indexes) > 0 {
+		var index_names []string
+
+		for _, idx := range indexes {
+			index_names = append(index_names, idx.Name)
+		}
+
+		w.printf("    INDEX { %s }\n", strings.Join(index_names, ", "))
+	}
+
+	w.printf("    ::= %s\n\n", w.oid_reference(nd.Oid))
+}
+
+func (w *smi_writer) write_table(tb *OMFTable) {
+	entry_type := smi_entry_type_name(tb.Entry.Name)
+
+	if entry_type == "" {
+		return
+	}
+
+	w.write_object_type(&tb.OMFNode, "SEQUENCE OF "+entry_type, nil)
+
+	w.write_object_type(&tb.Entry, entry_type, tb.Indexes)
+
+	var members []string
+
+	for _, col := range tb.Columns {
+		member_syntax := "OCTET STRING"
+
+		if col.Type != nil {
+			member_syntax = w.base_syntax(col.Type)
+		}
+
+		members = append(members, fmt.Sprintf("    %s %s", col.Name, member_syntax))
+	}
+
+	w.printf("%s ::= SEQUENCE {\n%s\n}\n\n", entry_type, strings.Join(members, ",\n"))
+
+	for _, col := range tb.Columns {
+		w.write_object_type(&col, w.type_syntax(col.Type), nil)
+	}
+}
+
+func (w *smi_writer) write_notification(nf *OMFNotification) {
+	w.require_macro("NOTIFICATION-TYPE")
+
+	w.printf("%s NOTIFICATION-TYPE\n", nf.Name)
+
+	if len(nf.Objects) > 0 {
+		var object_names []string
+
+		for _, obj := range nf.Objects {
+			object_names = append(object_names, obj.Name)
+		}
+
+		w.printf("    OBJECTS { %s }\n", strings.Join(object_names, ", "))
+	}
+
+	w.printf("    STATUS %s\n", smi_status(nf.Status))
+
+	w.write_description("DESCRIPTION", nf.Description)
+
+	w.printf("    ::= %s\n\n", w.oid_reference(nf.Oid))
+}
+
+func (w *smi_writer) write_group(gr *OMFGroup) {
+	macro, members_keyword := "OBJECT-GROUP", "OBJECTS"
+
+	if gr.Decl == "NotificationGroup" {
+		macro, members_keyword = "NOTIFICATION-GROUP", "NOTIFICATIONS"
+	}
+
+	w.require_macro(macro)
+
+	w.printf("%s %s\n", gr.Name, macro)
+
+	w.printf("    %s { %s }\n", members_keyword, omf_node_names(gr.Members))
+
+	w.printf("    STATUS %s\n", smi_status(gr.Status))
+
+	w.write_description("DESCRIPTION", gr.Description)
+
+	w.printf("    ::= %s\n\n", w.oid_reference(gr.Oid))
+}
+
+func (w *smi_writer) write_compliance_items(keyword string, items []OMFComplianceItem) {
+	for _, item := range items {
+		w.printf("        %s %s\n", keyword, item.Name)
+
+		w.printf("            DESCRIPTION\n                %s\n", smi_quote(item.Description))
+	}
+}
+
+func (w *smi_writer) write_compliance(cp *OMFCompliance) {
+	w.require_macro("MODULE-COMPLIANCE")
+
+	w.printf("%s MODULE-COMPLIANCE\n", cp.Name)
+
+	w.printf("    STATUS %s\n", smi_status(cp.Status))
+
+	w.write_description("DESCRIPTION", cp.Description)
+
+	modules := cp.Modules
+
+	if len(modules) == 0 {
+		modules = []OMFComplianceModule{{}}
+	}
+
+	for _, cp_mod := range modules {
+		if cp_mod.ModName == "" || cp_mod.ModName == w.module.Name {
+			w.printf("    MODULE -- this module\n")
+		} else {
+			w.printf("    MODULE %s\n", cp_mod.ModName)
+		}
+
+		if len(cp_mod.MandatoryGroups) > 0 {
+			w.printf("        MANDATORY-GROUPS { %s }\n", strings.Join(cp_mod.MandatoryGroups, ", "))
+		}
+
+		w.write_compliance_items("GROUP", cp_mod.Groups)
+
+		w.write_compliance_items("OBJECT", cp_mod.Objects)
+	}
+
+	w.printf("    ::= %s\n\n", w.oid_reference(cp.Oid))
+}
+
+func (w *smi_writer) write_other_node(nd *OMFNode) {
+	if nd.Name == w.identity_name || !smi_identifier_regex.MatchString(nd.Name) {
+		return
+	}
+
+	if nd.Kind == "Column" || nd.Kind == "Row" {
+		return
+	}
+
+	switch nd.Decl {
+	case "ObjectIdentity":
+		w.write_object_identity(nd)
+	default:
+		w.write_object_identifier(nd)
+	}
+}
+
+func (w *smi_writer) keep_import(module string, name string) {
+	if _, ok := smi_macro_modules[name]; ok || name == "TRAP-TYPE" {
+		return
+	}
+
+	if _, ok := smi_base_type_modules[name]; ok {
+		return
+	}
+
+	if mapped_module, ok := smi_v1_import_modules[module]; ok {
+		if mapped_module == "" {
+			return
+		}
+
+		module = mapped_module
+	}
+
+	if mapped_name, ok := smi_v1_import_names[name]; ok {
+		name = mapped_name
+	}
+
+	w.require(module, name)
+}
+
+func (w *smi_writer) write_imports(out *strings.Builder) {
+	for _, imp := range w.module.Imports {
+		for _, name := range imp.ImportedNodes {
+			w.keep_import(imp.ModName, name)
+		}
+	}
+
+	if len(w.required) == 0 {
+		return
+	}
+
+	var modules []string
+
+	for module := range w.required {
+		modules = append(modules, module)
+	}
+
+	sort.Strings(modules)
+
+	out.WriteString("IMPORTS\n")
+
+	for idx, module := range modules {
+		var names []string
+
+		for name := range w.required[module] {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+
+		fmt.Fprintf(out, "    %s\n        FROM %s", strings.Join(names, ", "), module)
+
+		if idx == len(modules)-1 {
+			out.WriteString(";\n\n")
+		} else {
+			out.WriteString("\n")
+		}
+	}
+}
+
+func render_smi_module(mod *OMFModule) (string, error) {
+	w := new_smi_writer(mod)
+
+	w.write_module_identity()
+
+	for _, tp := range mod.Types {
+		w.write_type(&tp)
+	}
+
+	for _, nd := range mod.OtherNodes {
+		w.write_other_node(&nd)
+	}
+
+	for _, sc := range mod.Scalars {
+		w.write_object_type(&sc.OMFNode, w.type_syntax(sc.Type), nil)
+	}
+
+	for _, tb := range mod.Tables {
+		w.write_table(&tb)
+	}
+
+	for _, nf := range mod.Notifications {
+		w.write_notification(&nf)
+	}
+
+	for _, gr := range mod.Groups {
+		w.write_group(&gr)
+	}
+
+	for _, cp := range mod.Compliances {
+		w.write_compliance(&cp)
+	}
+
+	var out strings.Builder
+
+	fmt.Fprintf(&out, "%s DEFINITIONS ::= BEGIN\n\n", mod.Name)
+
+	w.write_imports(&out)
+
+	out.WriteString(w.body.String())
+
+	out.WriteString("END\n")
+
+	if w.err != nil {
+		return "", fmt.Errorf("%s: %w", mod.Name, w.err)
+	}
+
+	return out.String(), nil
+}
+
+func GenerateSmiModule(mod OMFModule) (string, error) {
+	if !smi_identifier_regex.MatchString(mod.Name) {
+		return "", errors.New("module name is not a valid SMI module identifier")
+	}
+
+	return render_smi_module(&mod)
+}
+
+func WriteSmiModule(out io.Writer, mod OMFModule) error {
+	source, err := GenerateSmiModule(mod)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(out, source)
+
+	return err
+}
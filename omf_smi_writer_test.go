@@ -0,0 +1,107 @@
+package omifier
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateSmiModule(t *testing.T) {
+	tests := []struct {
+		module   string
+		contains []string
+		excludes []string
+	}{
+		{"ACME-TEST-MIB", []string{
+			"acmeTestMIB MODULE-IDENTITY\n",
+			"SYNTAX DisplayString (SIZE (0..64))\n",
+			"acmePortName OBJECT-TYPE\n    SYNTAX DisplayString\n",
+			"acmePortMac OBJECT-TYPE\n    SYNTAX MacAddress\n",
+		}, []string{
+			"(SIZE (0..255))",
+			"(SIZE (6))",
+			"synthesized",
+		}},
+		{"GAP-MIB", []string{
+			"-- GAP-MIB has no MODULE-IDENTITY, this one is synthesized.\n",
+			"gapMIB MODULE-IDENTITY\n",
+			"    ::= { gapRoot 8 }\n",
+			"    MODULE-IDENTITY, enterprises\n        FROM SNMPv2-SMI;\n",
+		}, nil},
+	}
+
+	for _, tt := range tests {
+		mod, err := GetOmfCommomStruct(omf_testdata_path, tt.module, false)
+
+		if err != nil {
+			t.Fatalf("GetOmfCommomStruct(%s): %s", tt.module, err)
+		}
+
+		source, err := GenerateSmiModule(mod)
+
+		if err != nil {
+			t.Fatalf("GenerateSmiModule(%s): %s", tt.module, err)
+		}
+
+		for _, want := range tt.contains {
+			if !strings.Contains(source, want) {
+				t.Errorf("%s: rendered SMI has no %q", tt.module, want)
+			}
+		}
+
+		for _, unwanted := range tt.excludes {
+			if strings.Contains(source, unwanted) {
+				t.Errorf("%s: rendered SMI has %q", tt.module, unwanted)
+			}
+		}
+	}
+}
+
+func TestGenerateSmiModuleRejectsInteger64(t *testing.T) {
+	mod := OMFModule{
+		Name: "INT64-MIB",
+		Scalars: []OMFScalar{{OMFNode: OMFNode{
+			Name:   "int64Value",
+			Oid:    "1.3.6.1.4.1.77781.1",
+			Access: "ReadOnly",
+			Type:   &OMFType{Name: "Integer64", BaseType: "Integer64"},
+		}}},
+	}
+
+	_, err := GenerateSmiModule(mod)
+
+	if err == nil || !strings.Contains(err.Error(), "Integer64") {
+		t.Errorf("GenerateSmiModule = %v, want an Integer64 error", err)
+	}
+}
+
+func TestSynthesizedModuleIdentity(t *testing.T) {
+	tests := []struct {
+		name          string
+		mod           OMFModule
+		identity_name string
+		identity_oid  string
+	}{
+		{"taken names", OMFModule{
+			Name:       "SINGLE-MIB",
+			Imports:    []OMFImport{{ModName: "OTHER-MIB", ImportedNodes: []string{"singleMIBIdentity"}}},
+			OtherNodes: []OMFNode{{Name: "singleMIB", Oid: "1.3.6.1.4.1.77795", Kind: "Node"}},
+			Scalars:    []OMFScalar{{OMFNode{Name: "singleValue", Oid: "1.3.6.1.4.1.77795.1", Kind: "Scalar"}}},
+		}, "singleMIBIdentityIdentity", "1.3.6.1.4.1.77795.2"},
+		{"scalar root", OMFModule{
+			Name:    "LONE-MIB",
+			Scalars: []OMFScalar{{OMFNode{Name: "loneValue", Oid: "1.3.6.1.4.1.77796.1.1", Kind: "Scalar"}}},
+		}, "loneMIB", "1.3.6.1.4.1.77796.1.2"},
+	}
+
+	for _, tt := range tests {
+		w := new_smi_writer(&tt.mod)
+
+		if !w.synthesized || w.identity_name != tt.identity_name || w.identity_oid != tt.identity_oid {
+			t.Errorf("%s: identity is %s (%s), want %s (%s)", tt.name, w.identity_name, w.identity_oid, tt.identity_name, tt.identity_oid)
+		}
+	}
+
+	if quoted := smi_quote(`a "quoted" word`); quoted != `"a 'quoted' word"` {
+		t.Errorf("smi_quote = %s", quoted)
+	}
+}
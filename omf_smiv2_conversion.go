@@ -0,0 +1,318 @@
+package omifier
+
+import (
+	"slices"
+
+	"github.com/belqlabs/omf-gosmi/parser"
+	gosmi_types "github.com/belqlabs/omf-gosmi/types"
+)
+
+type OMFConversion struct {
+	Object string
+	Field  string
+	From   string
+	To     string
+}
+
+type OMFConversionReport struct {
+	ModuleName   string
+	FromLanguage string
+	ToLanguage   string
+	Conversions  []OMFConversion `json:",omitempty"`
+
+	source_syntax map[string]string
+	source_access map[string]string
+}
+
+var smiv2_status_conversions = map[string]string{
+	"Mandatory": "Current",
+	"Optional":  "Obsolete",
+}
+
+var smiv2_type_name_conversions = map[string]string{
+	"Counter":        "Counter32",
+	"Gauge":          "Gauge32",
+	"NetworkAddress": "IpAddress",
+}
+
+var smiv2_import_conversions = map[string][2]string{
+	"RFC-1212::OBJECT-TYPE":      {"SNMPv2-SMI", "OBJECT-TYPE"},
+	"RFC-1215::TRAP-TYPE":        {"SNMPv2-SMI", "NOTIFICATION-TYPE"},
+	"RFC1213-MIB::DisplayString": {"SNMPv2-TC", "DisplayString"},
+	"RFC1213-MIB::PhysAddress":   {"SNMPv2-TC", "PhysAddress"},
+}
+
+// gosmi already maps the SMIv1 types and imports to their SMIv2 names, so
+// what gets converted is read from the module source as written.
+func (r *OMFConversionReport) read_source(parsed_module *parser.Module) {
+	r.source_syntax = make(map[string]string)
+
+	r.source_access = make(map[string]string)
+
+	for _, nd := range parsed_module.Body.Nodes {
+		if nd.ObjectType == nil {
+			continue
+		}
+
+		if nd.ObjectType.Syntax.Type != nil {
+			r.source_syntax[string(nd.Name)] = string(nd.ObjectType.Syntax.Type.Name)
+		}
+
+		r.source_access[string(nd.Name)] = string(nd.ObjectType.Access)
+	}
+}
+
+func source_imports(parsed_module *parser.Module) []OMFImport {
+	var imports []OMFImport
+
+	for _, imp := range parsed_module.Body.Imports {
+		omf_import := OMFImport{ModName: string(imp.Module)}
+
+		for _, name := range imp.Names {
+			omf_import.ImportedNodes = append(omf_import.ImportedNodes, string(name))
+		}
+
+		imports = append(imports, omf_import)
+	}
+
+	return imports
+}
+
+func (r *OMFConversionReport) convert(object string, field string, from string, to string) string {
+	if from == to {
+		return from
+	}
+
+	conversion := OMFConversion{
+		Object: object,
+		Field:  field,
+		From:   from,
+		To:     to,
+	}
+
+	// Index, notification and group references repeat conversions of
+	// objects that are already reported.
+	if !slices.Contains(r.Conversions, conversion) {
+		r.Conversions = append(r.Conversions, conversion)
+	}
+
+	return to
+}
+
+func (r *OMFConversionReport) convert_status(object string, field string, status string) string {
+	converted, found := smiv2_status_conversions[status]
+
+	if !found {
+		return status
+	}
+
+	return r.convert(object, field, status, converted)
+}
+
+func (r *OMFConversionReport) convert_type(object string, written string, tp *OMFType) {
+	if tp == nil {
+		return
+	}
+
+	if written == "" {
+		written = tp.Name
+	}
+
+	converted, found := smiv2_type_name_conversions[written]
+
+	if found {
+		tp.Name = r.convert(object, "Type.Name", written, converted)
+	}
+
+	tp.Status = r.convert_status(object, "Type.Status", tp.Status)
+}
+
+func (r *OMFConversionReport) convert_node(nd *OMFNode) {
+	if nd.Decl == "TrapType" {
+		nd.Decl = r.convert(nd.Name, "Decl", "TrapType", "NotificationType")
+
+		if nd.Status == "Unknown" || nd.Status == "" {
+			nd.Status = r.convert(nd.Name, "Status", nd.Status, "Current")
+		}
+	}
+
+	if r.source_access[nd.Name] == string(parser.AccessWriteOnly) {
+		nd.Access = r.convert(nd.Name, "Access", string(parser.AccessWriteOnly), "ReadWrite")
+	}
+
+	nd.Status = r.convert_status(nd.Name, "Status", nd.Status)
+
+	if nd.Type != nil {
+		converted_type := *nd.Type
+
+		r.convert_type(nd.Name, r.source_syntax[nd.Name], &converted_type)
+
+		nd.Type = &converted_type
+	}
+}
+
+func (r *OMFConversionReport) convert_nodes(nds []OMFNode) []OMFNode {
+	converted_nds := append([]OMFNode(nil), nds...)
+
+	for idx := range converted_nds {
+		r.convert_node(&converted_nds[idx])
+	}
+
+	return converted_nds
+}
+
+func (r *OMFConversionReport) convert_imports(imports []OMFImport) []OMFImport {
+	var converted_imports []OMFImport
+
+	imports_idx := make(map[string]int)
+
+	for _, imp := range imports {
+		for _, imported_node := range imp.ImportedNodes {
+			mod_name, node_name := imp.ModName, imported_node
+
+			converted, found := smiv2_import_conversions[mod_name+"::"+node_name]
+
+			if found {
+				mod_name, node_name = converted[0], converted[1]
+			} else if smi_v1_import_modules[mod_name] != "" {
+				mod_name = smi_v1_import_modules[mod_name]
+
+				if smi_v1_import_names[node_name] != "" {
+					node_name = smi_v1_import_names[node_name]
+				}
+			}
+
+			r.convert(imp.ModName, "Import", imp.ModName+"::"+imported_node, mod_name+"::"+node_name)
+
+			idx, exists := imports_idx[mod_name]
+
+			if !exists {
+				idx = len(converted_imports)
+
+				imports_idx[mod_name] = idx
+
+				converted_imports = append(converted_imports, OMFImport{ModName: mod_name})
+			}
+
+			converted_imports[idx].ImportedNodes = append(converted_imports[idx].ImportedNodes, node_name)
+		}
+	}
+
+	return converted_imports
+}
+
+func convert_omf_module_to_smiv2(mod *OMFModule, parsed_module *parser.Module) OMFConversionReport {
+	report := OMFConversionReport{
+		ModuleName:   mod.Name,
+		FromLanguage: mod.Language,
+		ToLanguage:   "SMIv2",
+	}
+
+	if mod.Language != "SMIv1" {
+		report.ToLanguage = mod.Language
+
+		return report
+	}
+
+	mod.Language = report.convert(mod.Name, "Language", mod.Language, "SMIv2")
+
+	mod.LanguageVersion = int(gosmi_types.LanguageSMIv2)
+
+	if parsed_module != nil {
+		report.read_source(parsed_module)
+
+		mod.Imports = source_imports(parsed_module)
+	}
+
+	mod.Imports = report.convert_imports(mod.Imports)
+
+	for idx := range mod.Types {
+		report.convert_type(mod.Types[idx].Name, "", &mod.Types[idx])
+	}
+
+	for idx := range mod.TextualConventions {
+		report.convert_type(mod.TextualConventions[idx].Name, "", &mod.TextualConventions[idx].OMFType)
+	}
+
+	for idx := range mod.Scalars {
+		report.convert_node(&mod.Scalars[idx].OMFNode)
+	}
+
+	for idx := range mod.Indexes {
+		report.convert_node(&mod.Indexes[idx].OMFNode)
+	}
+
+	for idx := range mod.Tables {
+		tb := &mod.Tables[idx]
+
+		report.convert_node(&tb.OMFNode)
+
+		report.convert_node(&tb.Entry)
+
+		tb.Columns = report.convert_nodes(tb.Columns)
+
+		tb.Indexes = append([]OMFIndex(nil), tb.Indexes...)
+
+		for idx_idx := range tb.Indexes {
+			report.convert_node(&tb.Indexes[idx_idx].OMFNode)
+		}
+	}
+
+	for idx := range mod.Notifications {
+		report.convert_node(&mod.Notifications[idx].OMFNode)
+
+		mod.Notifications[idx].Objects = report.convert_nodes(mod.Notifications[idx].Objects)
+	}
+
+	for idx := range mod.Groups {
+		report.convert_node(&mod.Groups[idx].OMFNode)
+
+		mod.Groups[idx].Members = report.convert_nodes(mod.Groups[idx].Members)
+	}
+
+	for idx := range mod.Compliances {
+		report.convert_node(&mod.Compliances[idx].OMFNode)
+	}
+
+	mod.OtherNodes = report.convert_nodes(mod.OtherNodes)
+
+	return report
+}
+
+func ConvertOmfModuleToSMIv2(mod OMFModule) (OMFModule, OMFConversionReport) {
+	mod.Imports = append([]OMFImport(nil), mod.Imports...)
+
+	mod.Types = append([]OMFType(nil), mod.Types...)
+
+	mod.TextualConventions = append([]OMFTextualConvention(nil), mod.TextualConventions...)
+
+	mod.Scalars = append([]OMFScalar(nil), mod.Scalars...)
+
+	mod.Indexes = append([]OMFIndex(nil), mod.Indexes...)
+
+	mod.Tables = append([]OMFTable(nil), mod.Tables...)
+
+	mod.Notifications = append([]OMFNotification(nil), mod.Notifications...)
+
+	mod.Groups = append([]OMFGroup(nil), mod.Groups...)
+
+	mod.Compliances = append([]OMFCompliance(nil), mod.Compliances...)
+
+	report := convert_omf_module_to_smiv2(&mod, read_module_source(mod.Path))
+
+	mod.Conversion = &report
+
+	return mod, report
+}
+
+func GetOmfSMIv2Struct(path string, module_name string) (OMFModule, error) {
+	omf_module, err := GetOmfCommomStruct(path, module_name, false)
+
+	if err != nil {
+		return omf_module, err
+	}
+
+	converted_module, _ := ConvertOmfModuleToSMIv2(omf_module)
+
+	return converted_module, nil
+}
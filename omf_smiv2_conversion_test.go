@@ -0,0 +1,109 @@
+package omifier
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestConvertOmfModuleToSMIv2(t *testing.T) {
+	mod, err := GetOmfSMIv2Struct(omf_testdata_path, "ACME-V1-MIB")
+
+	if err != nil {
+		t.Fatalf("GetOmfSMIv2Struct: %s", err)
+	}
+
+	if mod.Conversion == nil || mod.Language != "SMIv2" || mod.LanguageVersion != 2 {
+		t.Fatalf("ACME-V1-MIB was not converted, language is %s (%d)", mod.Language, mod.LanguageVersion)
+	}
+
+	tests := []OMFConversion{
+		{Object: "ACME-V1-MIB", Field: "Language", From: "SMIv1", To: "SMIv2"},
+		{Object: "RFC1155-SMI", Field: "Import", From: "RFC1155-SMI::Counter", To: "SNMPv2-SMI::Counter32"},
+		{Object: "RFC1155-SMI", Field: "Import", From: "RFC1155-SMI::Gauge", To: "SNMPv2-SMI::Gauge32"},
+		{Object: "RFC1213-MIB", Field: "Import", From: "RFC1213-MIB::DisplayString", To: "SNMPv2-TC::DisplayString"},
+		{Object: "RFC-1215", Field: "Import", From: "RFC-1215::TRAP-TYPE", To: "SNMPv2-SMI::NOTIFICATION-TYPE"},
+		{Object: "acmeV1Packets", Field: "Type.Name", From: "Counter", To: "Counter32"},
+		{Object: "acmeV1Load", Field: "Type.Name", From: "Gauge", To: "Gauge32"},
+		{Object: "acmeV1Load", Field: "Status", From: "Optional", To: "Obsolete"},
+		{Object: "acmeV1Reset", Field: "Access", From: "write-only", To: "ReadWrite"},
+		{Object: "acmeV1ColdStart", Field: "Decl", From: "TrapType", To: "NotificationType"},
+	}
+
+	for _, tt := range tests {
+		if !slices.Contains(mod.Conversion.Conversions, tt) {
+			t.Errorf("conversion report has no %+v", tt)
+		}
+	}
+
+	for _, conversion := range mod.Conversion.Conversions {
+		if conversion.Field == "Access" && conversion.Object != "acmeV1Reset" {
+			t.Errorf("conversion report changes the access of %s", conversion.Object)
+		}
+	}
+
+	for _, sc := range mod.Scalars {
+		if sc.Name == "acmeV1Reset" && sc.Access != "ReadWrite" {
+			t.Errorf("acmeV1Reset has access %s, want ReadWrite", sc.Access)
+		}
+	}
+
+	for _, imp := range mod.Imports {
+		if _, found := smi_v1_import_modules[imp.ModName]; found {
+			t.Errorf("converted module still imports from %s", imp.ModName)
+		}
+	}
+}
+
+func TestConvertOmfModuleToSMIv2KeepsSMIv2(t *testing.T) {
+	mod, err := GetOmfSMIv2Struct(omf_testdata_path, "ACME-TEST-MIB")
+
+	if err != nil {
+		t.Fatalf("GetOmfSMIv2Struct: %s", err)
+	}
+
+	if mod.Conversion == nil || len(mod.Conversion.Conversions) != 0 || mod.Conversion.ToLanguage != "SMIv2" {
+		t.Errorf("SMIv2 module was converted: %+v", mod.Conversion)
+	}
+}
+
+func TestConvertOmfModuleToSMIv2ReportsReferences(t *testing.T) {
+	mod := OMFModule{
+		Name:     "REF-V1-MIB",
+		Language: "SMIv1",
+		Scalars: []OMFScalar{
+			{OMFNode{Name: "refV1Load", Status: "Optional", Type: &OMFType{Name: "Gauge32", Status: "Mandatory"}}},
+		},
+		Notifications: []OMFNotification{
+			{OMFNode: OMFNode{Name: "refV1Trap", Decl: "TrapType"}, Objects: []OMFNode{
+				{Name: "refV1Load", Status: "Optional"},
+				{Name: "refV1Imported", Status: "Mandatory"},
+			}},
+		},
+	}
+
+	converted, report := ConvertOmfModuleToSMIv2(mod)
+
+	if status := converted.Notifications[0].Objects[1].Status; status != "Current" {
+		t.Errorf("refV1Imported has status %s, want Current", status)
+	}
+
+	want := OMFConversion{Object: "refV1Imported", Field: "Status", From: "Mandatory", To: "Current"}
+
+	if !slices.Contains(report.Conversions, want) {
+		t.Errorf("conversion report has no %+v: %+v", want, report.Conversions)
+	}
+
+	load := OMFConversion{Object: "refV1Load", Field: "Status", From: "Optional", To: "Obsolete"}
+
+	count := 0
+
+	for _, conversion := range report.Conversions {
+		if conversion == load {
+			count++
+		}
+	}
+
+	if count != 1 {
+		t.Errorf("%+v is reported %d times, want once", load, count)
+	}
+}
@@ -0,0 +1,347 @@
+package omifier
+
+import (
+	"errors"
+	"io"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+
+	gosmi "github.com/belqlabs/omf-gosmi"
+	"gopkg.in/yaml.v2"
+)
+
+type OMFSnmpExporterLookup struct {
+	SourceIndexes     []string `yaml:"source_indexes"`
+	Lookup            string   `yaml:"lookup"`
+	DropSourceIndexes bool     `yaml:"drop_source_indexes,omitempty"`
+}
+
+type OMFSnmpExporterOverride struct {
+	Type       string           `yaml:"type,omitempty"`
+	Ignore     bool             `yaml:"ignore,omitempty"`
+	EnumValues map[int64]string `yaml:"enum_values,omitempty"`
+}
+
+type OMFSnmpExporterModule struct {
+	Walk      []string                           `yaml:"walk"`
+	Lookups   []OMFSnmpExporterLookup            `yaml:"lookups,omitempty"`
+	Overrides map[string]OMFSnmpExporterOverride `yaml:"overrides,omitempty"`
+}
+
+// The config is snmp_exporter's generator.yml, which the generator turns
+// into snmp.yml against the same MIBs. Enum overrides also list the labels
+// read from OMFEnum, so the mapping can be reviewed without the MIB.
+type OMFSnmpExporterConfig struct {
+	Modules map[string]OMFSnmpExporterModule `yaml:"modules"`
+}
+
+var snmp_exporter_string_types = map[string]string{
+	"DisplayString":   "DisplayString",
+	"SnmpAdminString": "DisplayString",
+	"PhysAddress":     "PhysAddress48",
+	"MacAddress":      "PhysAddress48",
+	"DateAndTime":     "DateAndTime",
+	"InetAddress":     "InetAddress",
+	"InetAddressIPv4": "InetAddressIPv4",
+	"InetAddressIPv6": "InetAddressIPv6",
+	"IpAddress":       "IpAddr",
+}
+
+// Counters are recognised by the named type a type resolves to, nearest
+// first, so a textual convention on Counter64 is a counter unless it is
+// known to hold a gauge.
+var snmp_exporter_metric_types = map[string]string{
+	"Counter":             "counter",
+	"Counter32":           "counter",
+	"Counter64":           "counter",
+	"CounterBasedGauge64": "gauge",
+}
+
+func snmp_exporter_module_name(module_name string) string {
+	return strings.ReplaceAll(strings.ToLower(module_name), "-", "_")
+}
+
+// Without a type chain only the type a node names is resolved.
+func snmp_exporter_type(tp *OMFType, chain []status_type) string {
+	if tp == nil {
+		return ""
+	}
+
+	if exporter_type, ok := snmp_exporter_string_types[tp.Name]; ok {
+		return exporter_type
+	}
+
+	switch tp.BaseType {
+	case "Enum":
+		return "EnumAsInfo"
+	case "Bits":
+		return "Bits"
+	case "OctetString":
+		if yang_format_string_regex.MatchString(tp.Format) {
+			return "DisplayString"
+		}
+
+		return "OctetString"
+	case "ObjectIdentifier":
+		return ""
+	}
+
+	if chain == nil {
+		chain = []status_type{{name: tp.Name}}
+	}
+
+	for _, named := range chain {
+		if metric_type, ok := snmp_exporter_metric_types[named.name]; ok {
+			return metric_type
+		}
+	}
+
+	return "gauge"
+}
+
+func snmp_exporter_enum_values(tp *OMFType) map[int64]string {
+	if tp == nil || tp.BaseType != "Enum" || len(tp.Enum) == 0 {
+		return nil
+	}
+
+	enum_values := make(map[int64]string)
+
+	for _, label := range slices.Sorted(maps.Keys(tp.Enum)) {
+		if _, ok := enum_values[tp.Enum[label]]; !ok {
+			enum_values[tp.Enum[label]] = label
+		}
+	}
+
+	return enum_values
+}
+
+func snmp_exporter_override(nd *OMFNode, chains map[string][]status_type) (OMFSnmpExporterOverride, bool) {
+	if nd.Access == "NotAccessible" || nd.Access == "Notify" {
+		return OMFSnmpExporterOverride{}, false
+	}
+
+	exporter_type := snmp_exporter_type(nd.Type, chains[nd.Name])
+
+	if exporter_type == "" {
+		return OMFSnmpExporterOverride{}, false
+	}
+
+	return OMFSnmpExporterOverride{Type: exporter_type, EnumValues: snmp_exporter_enum_values(nd.Type)}, true
+}
+
+func snmp_exporter_descriptive_column(tb *OMFTable) (OMFNode, bool) {
+	var candidate *OMFNode
+
+	for idx := range tb.Columns {
+		col := &tb.Columns[idx]
+
+		if col.Access == "NotAccessible" || snmp_exporter_type(col.Type, nil) != "DisplayString" {
+			continue
+		}
+
+		if strings.HasSuffix(col.Name, "Descr") || strings.HasSuffix(col.Name, "Name") || strings.HasSuffix(col.Name, "Alias") {
+			return *col, true
+		}
+
+		if candidate == nil {
+			candidate = col
+		}
+	}
+
+	if candidate == nil {
+		return OMFNode{}, false
+	}
+
+	return *candidate, true
+}
+
+func snmp_exporter_lookups(tb *OMFTable, column_tables map[string]*OMFTable) []OMFSnmpExporterLookup {
+	var lookups []OMFSnmpExporterLookup
+
+	for _, idx := range tb.Indexes {
+		owner, ok := column_tables[idx.Name]
+
+		if !ok || len(owner.Indexes) != 1 || owner.Indexes[0].Name != idx.Name {
+			continue
+		}
+
+		descr, ok := snmp_exporter_descriptive_column(owner)
+
+		if !ok {
+			continue
+		}
+
+		lookups = append(lookups, OMFSnmpExporterLookup{
+			SourceIndexes: []string{idx.Name},
+			Lookup:        descr.Name,
+		})
+	}
+
+	return lookups
+}
+
+func snmp_exporter_walk(roots []string) []string {
+	sort.Slice(roots, func(i, j int) bool {
+		return len(split_oid_arcs(roots[i])) < len(split_oid_arcs(roots[j]))
+	})
+
+	var walk []string
+
+	for _, root := range roots {
+		covered := false
+
+		for _, walked := range walk {
+			if root == walked || strings.HasPrefix(root, walked+".") {
+				covered = true
+
+				break
+			}
+		}
+
+		if !covered {
+			walk = append(walk, root)
+		}
+	}
+
+	sort.Strings(walk)
+
+	return walk
+}
+
+func create_snmp_exporter_module(mod *OMFModule, chains map[string][]status_type) OMFSnmpExporterModule {
+	exporter_module := OMFSnmpExporterModule{
+		Overrides: make(map[string]OMFSnmpExporterOverride),
+	}
+
+	var roots []string
+
+	for _, sc := range mod.Scalars {
+		override, ok := snmp_exporter_override(&sc.OMFNode, chains)
+
+		if !ok {
+			continue
+		}
+
+		arcs := split_oid_arcs(sc.Oid)
+
+		roots = append(roots, strings.Join(arcs[:len(arcs)-1], "."))
+
+		exporter_module.Overrides[sc.Name] = override
+	}
+
+	column_tables := make(map[string]*OMFTable)
+
+	for idx := range mod.Tables {
+		for _, col := range mod.Tables[idx].Columns {
+			column_tables[col.Name] = &mod.Tables[idx]
+		}
+	}
+
+	looked_up := make(map[string]bool)
+
+	for _, tb := range mod.Tables {
+		table_has_metrics := false
+
+		for _, col := range tb.Columns {
+			override, ok := snmp_exporter_override(&col, chains)
+
+			if !ok {
+				continue
+			}
+
+			table_has_metrics = true
+
+			exporter_module.Overrides[col.Name] = override
+		}
+
+		if !table_has_metrics {
+			continue
+		}
+
+		roots = append(roots, tb.Oid)
+
+		for _, lookup := range snmp_exporter_lookups(&tb, column_tables) {
+			if !looked_up[lookup.SourceIndexes[0]] {
+				looked_up[lookup.SourceIndexes[0]] = true
+
+				exporter_module.Lookups = append(exporter_module.Lookups, lookup)
+			}
+		}
+	}
+
+	exporter_module.Walk = snmp_exporter_walk(roots)
+
+	return exporter_module
+}
+
+func GenerateSnmpExporterConfig(mods ...OMFModule) (OMFSnmpExporterConfig, error) {
+	config := OMFSnmpExporterConfig{
+		Modules: make(map[string]OMFSnmpExporterModule),
+	}
+
+	for _, mod := range mods {
+		if mod.Name == "" {
+			return config, errors.New("cannot generate a snmp_exporter module for an unnamed module")
+		}
+
+		config.Modules[snmp_exporter_module_name(mod.Name)] = create_snmp_exporter_module(&mod, nil)
+	}
+
+	return config, nil
+}
+
+// Unlike GenerateSnmpExporterConfig, the modules are loaded, so counters are
+// also recognised through imported textual conventions.
+func GetSnmpExporterConfig(path string, module_names ...string) (OMFSnmpExporterConfig, error) {
+	config := OMFSnmpExporterConfig{
+		Modules: make(map[string]OMFSnmpExporterModule),
+	}
+
+	for _, module_name := range module_names {
+		err := init_gosmi(path, module_name)
+
+		if err != nil {
+			exit_gosmi()
+
+			return config, err
+		}
+
+		m, err := gosmi.GetModule(module_name)
+
+		if err != nil {
+			exit_gosmi()
+
+			return config, err
+		}
+
+		mod := omfy_module(&m)
+
+		chains := read_status_type_chains(&m)
+
+		exit_gosmi()
+
+		config.Modules[snmp_exporter_module_name(mod.Name)] = create_snmp_exporter_module(&mod, chains)
+	}
+
+	return config, nil
+}
+
+func WriteSnmpExporterConfig(out io.Writer, mods ...OMFModule) error {
+	config, err := GenerateSnmpExporterConfig(mods...)
+
+	if err != nil {
+		return err
+	}
+
+	encoded, err := yaml.Marshal(config)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = out.Write(encoded)
+
+	return err
+}
@@ -0,0 +1,136 @@
+package omifier
+
+import (
+	"bytes"
+	"reflect"
+	"slices"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestWriteSnmpExporterConfig(t *testing.T) {
+	mod, err := GetOmfCommomStruct(omf_testdata_path, "ACME-TEST-MIB", false)
+
+	if err != nil {
+		t.Fatalf("GetOmfCommomStruct: %s", err)
+	}
+
+	var out bytes.Buffer
+
+	err = WriteSnmpExporterConfig(&out, mod)
+
+	if err != nil {
+		t.Fatalf("WriteSnmpExporterConfig: %s", err)
+	}
+
+	var config OMFSnmpExporterConfig
+
+	err = yaml.UnmarshalStrict(out.Bytes(), &config)
+
+	if err != nil {
+		t.Fatalf("generator.yml does not decode: %s", err)
+	}
+
+	exporter_module, ok := config.Modules["acme_test_mib"]
+
+	if !ok {
+		t.Fatalf("generator.yml has no acme_test_mib module: %s", out.String())
+	}
+
+	if !slices.Equal(exporter_module.Walk, []string{"1.3.6.1.4.1.99999.1"}) {
+		t.Errorf("walk = %v", exporter_module.Walk)
+	}
+
+	want_lookups := []OMFSnmpExporterLookup{{SourceIndexes: []string{"acmePortIndex"}, Lookup: "acmePortName"}}
+
+	if !reflect.DeepEqual(exporter_module.Lookups, want_lookups) {
+		t.Errorf("lookups = %+v, want %+v", exporter_module.Lookups, want_lookups)
+	}
+
+	tests := []struct {
+		object string
+		tp     string
+	}{
+		{"acmeSystemName", "DisplayString"},
+		{"acmeUptime", "gauge"},
+		{"acmePortInOctets", "counter"},
+		{"acmePortMac", "PhysAddress48"},
+		{"acmePortState", "EnumAsInfo"},
+		{"acmePortIndex", ""},
+	}
+
+	for _, tt := range tests {
+		if override := exporter_module.Overrides[tt.object]; override.Type != tt.tp {
+			t.Errorf("override type of %s = %q, want %q", tt.object, override.Type, tt.tp)
+		}
+	}
+
+	want_enum_values := map[int64]string{0: "down", 1: "up", 2: "testing"}
+
+	if enum_values := exporter_module.Overrides["acmePortState"].EnumValues; !reflect.DeepEqual(enum_values, want_enum_values) {
+		t.Errorf("enum values of acmePortState = %v, want %v", enum_values, want_enum_values)
+	}
+
+	if enum_values := exporter_module.Overrides["acmeUptime"].EnumValues; enum_values != nil {
+		t.Errorf("enum values of acmeUptime = %v, want none", enum_values)
+	}
+}
+
+func TestSnmpExporterCounterTypes(t *testing.T) {
+	config, err := GetSnmpExporterConfig(omf_testdata_path, "COUNTER-MIB")
+
+	if err != nil {
+		t.Fatalf("GetSnmpExporterConfig: %s", err)
+	}
+
+	tests := []struct {
+		object string
+		tp     string
+	}{
+		{"counterPackets", "counter"},
+		{"counterQueueDepth", "gauge"},
+		{"counterOctets", "counter"},
+	}
+
+	for _, tt := range tests {
+		if override := config.Modules["counter_mib"].Overrides[tt.object]; override.Type != tt.tp {
+			t.Errorf("override type of %s = %q, want %q", tt.object, override.Type, tt.tp)
+		}
+	}
+
+	gauge := OMFType{Name: "CounterBasedGauge64", BaseType: "Unsigned64"}
+
+	if tp := snmp_exporter_type(&gauge, nil); tp != "gauge" {
+		t.Errorf("type of an unresolved CounterBasedGauge64 = %q, want gauge", tp)
+	}
+}
+
+func TestSnmpExporterLookupsForStringIndexes(t *testing.T) {
+	name_index := OMFIndex{OMFNode{Name: "userName", Oid: "1.3.6.1.4.1.77782.1.1.1", Access: "NotAccessible", Type: &OMFType{Name: "DisplayString", BaseType: "OctetString", Format: "255a"}}}
+
+	mod := OMFModule{
+		Name: "USER-MIB",
+		Tables: []OMFTable{{
+			OMFNode: OMFNode{Name: "userTable", Oid: "1.3.6.1.4.1.77782.1"},
+			Indexes: []OMFIndex{name_index},
+			Columns: []OMFNode{
+				name_index.OMFNode,
+				{Name: "userDescr", Oid: "1.3.6.1.4.1.77782.1.1.2", Access: "ReadOnly", Type: &OMFType{Name: "DisplayString", BaseType: "OctetString", Format: "255a"}},
+				{Name: "userLogins", Oid: "1.3.6.1.4.1.77782.1.1.3", Access: "ReadOnly", Type: &OMFType{Name: "Counter32", BaseType: "Unsigned32"}},
+			},
+		}},
+	}
+
+	config, err := GenerateSnmpExporterConfig(mod)
+
+	if err != nil {
+		t.Fatalf("GenerateSnmpExporterConfig: %s", err)
+	}
+
+	want_lookups := []OMFSnmpExporterLookup{{SourceIndexes: []string{"userName"}, Lookup: "userDescr"}}
+
+	if lookups := config.Modules["user_mib"].Lookups; !reflect.DeepEqual(lookups, want_lookups) {
+		t.Errorf("lookups = %+v, want %+v", lookups, want_lookups)
+	}
+}
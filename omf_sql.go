@@ -0,0 +1,515 @@
+package omifier
+
+import (
+	"crypto/md5"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+type OMFSQLDialect string
+
+const (
+	OMFSQLDialectPostgres OMFSQLDialect = "postgres"
+	OMFSQLDialectSQLite   OMFSQLDialect = "sqlite"
+)
+
+var sql_dialect_timestamp = map[OMFSQLDialect]string{
+	OMFSQLDialectPostgres: "TIMESTAMPTZ",
+	OMFSQLDialectSQLite:   "TEXT",
+}
+
+var sql_schema_statements = []string{
+	`CREATE TABLE IF NOT EXISTS omf_modules (
+	name TEXT PRIMARY KEY,
+	module_hash TEXT NOT NULL,
+	content_hash TEXT NOT NULL,
+	language TEXT NOT NULL,
+	language_version INTEGER NOT NULL,
+	last_updated {timestamp},
+	identity_name TEXT NOT NULL,
+	identity_oid TEXT NOT NULL,
+	organization TEXT NOT NULL,
+	contact_info TEXT NOT NULL,
+	description TEXT NOT NULL,
+	reference TEXT NOT NULL,
+	path TEXT NOT NULL
+)`,
+	`CREATE TABLE IF NOT EXISTS omf_imports (
+	module_name TEXT NOT NULL REFERENCES omf_modules (name) ON DELETE CASCADE,
+	imported_module TEXT NOT NULL,
+	imported_name TEXT NOT NULL,
+	PRIMARY KEY (module_name, imported_module, imported_name)
+)`,
+	`CREATE TABLE IF NOT EXISTS omf_revisions (
+	module_name TEXT NOT NULL REFERENCES omf_modules (name) ON DELETE CASCADE,
+	revision_date {timestamp} NOT NULL,
+	description TEXT NOT NULL,
+	PRIMARY KEY (module_name, revision_date)
+)`,
+	`CREATE TABLE IF NOT EXISTS omf_types (
+	module_name TEXT NOT NULL REFERENCES omf_modules (name) ON DELETE CASCADE,
+	name TEXT NOT NULL,
+	base_type TEXT NOT NULL,
+	decl TEXT NOT NULL,
+	status TEXT NOT NULL,
+	format TEXT NOT NULL,
+	units TEXT NOT NULL,
+	description TEXT NOT NULL,
+	reference TEXT NOT NULL,
+	PRIMARY KEY (module_name, name)
+)`,
+	`CREATE TABLE IF NOT EXISTS omf_nodes (
+	module_name TEXT NOT NULL REFERENCES omf_modules (name) ON DELETE CASCADE,
+	name TEXT NOT NULL,
+	node_hash TEXT NOT NULL,
+	content_hash TEXT NOT NULL,
+	oid TEXT NOT NULL,
+	kind TEXT NOT NULL,
+	decl TEXT NOT NULL,
+	access TEXT NOT NULL,
+	status TEXT NOT NULL,
+	description TEXT NOT NULL,
+	type_name TEXT NOT NULL,
+	type_base_type TEXT NOT NULL,
+	PRIMARY KEY (module_name, name)
+)`,
+	`CREATE INDEX IF NOT EXISTS omf_nodes_oid ON omf_nodes (oid)`,
+	`CREATE TABLE IF NOT EXISTS omf_enums (
+	module_name TEXT NOT NULL REFERENCES omf_modules (name) ON DELETE CASCADE,
+	owner_kind TEXT NOT NULL,
+	owner_name TEXT NOT NULL,
+	label TEXT NOT NULL,
+	value BIGINT NOT NULL,
+	PRIMARY KEY (module_name, owner_kind, owner_name, label)
+)`,
+	`CREATE TABLE IF NOT EXISTS omf_ranges (
+	module_name TEXT NOT NULL REFERENCES omf_modules (name) ON DELETE CASCADE,
+	owner_kind TEXT NOT NULL,
+	owner_name TEXT NOT NULL,
+	position INTEGER NOT NULL,
+	min_value BIGINT NOT NULL,
+	max_value BIGINT NOT NULL,
+	PRIMARY KEY (module_name, owner_kind, owner_name, position)
+)`,
+	`CREATE TABLE IF NOT EXISTS omf_tables (
+	module_name TEXT NOT NULL REFERENCES omf_modules (name) ON DELETE CASCADE,
+	name TEXT NOT NULL,
+	entry_name TEXT NOT NULL,
+	PRIMARY KEY (module_name, name)
+)`,
+	`CREATE TABLE IF NOT EXISTS omf_columns (
+	module_name TEXT NOT NULL,
+	table_name TEXT NOT NULL,
+	column_name TEXT NOT NULL,
+	position INTEGER NOT NULL,
+	PRIMARY KEY (module_name, table_name, column_name),
+	FOREIGN KEY (module_name, table_name) REFERENCES omf_tables (module_name, name) ON DELETE CASCADE
+)`,
+	`CREATE TABLE IF NOT EXISTS omf_indexes (
+	module_name TEXT NOT NULL,
+	table_name TEXT NOT NULL,
+	index_name TEXT NOT NULL,
+	position INTEGER NOT NULL,
+	PRIMARY KEY (module_name, table_name, index_name),
+	FOREIGN KEY (module_name, table_name) REFERENCES omf_tables (module_name, name) ON DELETE CASCADE
+)`,
+	`CREATE TABLE IF NOT EXISTS omf_notifications (
+	module_name TEXT NOT NULL REFERENCES omf_modules (name) ON DELETE CASCADE,
+	name TEXT NOT NULL,
+	PRIMARY KEY (module_name, name)
+)`,
+	`CREATE TABLE IF NOT EXISTS omf_notification_objects (
+	module_name TEXT NOT NULL,
+	notification_name TEXT NOT NULL,
+	object_name TEXT NOT NULL,
+	position INTEGER NOT NULL,
+	PRIMARY KEY (module_name, notification_name, position),
+	FOREIGN KEY (module_name, notification_name) REFERENCES omf_notifications (module_name, name) ON DELETE CASCADE
+)`,
+}
+
+var sql_module_child_tables = []string{
+	"omf_notification_objects",
+	"omf_notifications",
+	"omf_indexes",
+	"omf_columns",
+	"omf_tables",
+	"omf_ranges",
+	"omf_enums",
+	"omf_types",
+	"omf_revisions",
+	"omf_imports",
+}
+
+func sql_schema(dialect OMFSQLDialect) ([]string, error) {
+	timestamp, ok := sql_dialect_timestamp[dialect]
+
+	if !ok {
+		return nil, fmt.Errorf("unsupported sql dialect %q", dialect)
+	}
+
+	var statements []string
+
+	for _, statement := range sql_schema_statements {
+		statements = append(statements, strings.ReplaceAll(statement, "{timestamp}", timestamp))
+	}
+
+	return statements, nil
+}
+
+func sql_rebind(dialect OMFSQLDialect, query string) string {
+	if dialect != OMFSQLDialectPostgres {
+		return query
+	}
+
+	var rebound strings.Builder
+
+	placeholder := 0
+
+	for _, r := range query {
+		if r != '?' {
+			rebound.WriteRune(r)
+
+			continue
+		}
+
+		placeholder++
+
+		fmt.Fprintf(&rebound, "$%d", placeholder)
+	}
+
+	return rebound.String()
+}
+
+func sql_timestamp(dialect OMFSQLDialect, t time.Time) any {
+	if dialect == OMFSQLDialectSQLite {
+		return t.UTC().Format(time.RFC3339)
+	}
+
+	return t.UTC()
+}
+
+// ModuleHash and NodeHash leave out most of the stored columns, so rows are
+// compared by an md5 of everything they store.
+func sql_content_hash(v any) (string, error) {
+	data, err := json.Marshal(v)
+
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%x", md5.Sum(data)), nil
+}
+
+type sql_loader struct {
+	tx           *sql.Tx
+	dialect      OMFSQLDialect
+	module       *OMFModule
+	content_hash string
+}
+
+func (l *sql_loader) exec(query string, args ...any) error {
+	_, err := l.tx.Exec(sql_rebind(l.dialect, query), args...)
+
+	return err
+}
+
+func (l *sql_loader) insert_enum_and_ranges(owner_kind string, owner_name string, tp *OMFType) error {
+	for _, label := range smi_ordered_enum(tp.Enum) {
+		err := l.exec("INSERT INTO omf_enums (module_name, owner_kind, owner_name, label, value) VALUES (?, ?, ?, ?, ?)", l.module.Name, owner_kind, owner_name, label, tp.Enum[label])
+
+		if err != nil {
+			return err
+		}
+	}
+
+	for position, rg := range tp.Ranges {
+		err := l.exec("INSERT INTO omf_ranges (module_name, owner_kind, owner_name, position, min_value, max_value) VALUES (?, ?, ?, ?, ?, ?)", l.module.Name, owner_kind, owner_name, position, rg[0], rg[1])
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (l *sql_loader) upsert_module() error {
+	mod := l.module
+
+	return l.exec(`INSERT INTO omf_modules (name, module_hash, content_hash, language, language_version, last_updated, identity_name, identity_oid, organization, contact_info, description, reference, path)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (name) DO UPDATE SET
+	module_hash = excluded.module_hash,
+	content_hash = excluded.content_hash,
+	language = excluded.language,
+	language_version = excluded.language_version,
+	last_updated = excluded.last_updated,
+	identity_name = excluded.identity_name,
+	identity_oid = excluded.identity_oid,
+	organization = excluded.organization,
+	contact_info = excluded.contact_info,
+	description = excluded.description,
+	reference = excluded.reference,
+	path = excluded.path
+WHERE omf_modules.content_hash <> excluded.content_hash`,
+		mod.Name, mod.ModuleHash, l.content_hash, mod.Language, mod.LanguageVersion, sql_timestamp(l.dialect, mod.LastUpdated), mod.IdentityName, mod.IdentityOid, mod.Organization, mod.ContactInfo, mod.Description, mod.Reference, mod.Path)
+}
+
+func (l *sql_loader) replace_module_children() error {
+	mod := l.module
+
+	for _, table := range sql_module_child_tables {
+		err := l.exec("DELETE FROM "+table+" WHERE module_name = ?", mod.Name)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, imp := range mod.Imports {
+		for _, name := range imp.ImportedNodes {
+			err := l.exec("INSERT INTO omf_imports (module_name, imported_module, imported_name) VALUES (?, ?, ?) ON CONFLICT DO NOTHING", mod.Name, imp.ModName, name)
+
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, rev := range mod.Revisions {
+		err := l.exec("INSERT INTO omf_revisions (module_name, revision_date, description) VALUES (?, ?, ?) ON CONFLICT DO NOTHING", mod.Name, sql_timestamp(l.dialect, rev.Date), rev.Description)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, tp := range mod.Types {
+		err := l.exec("INSERT INTO omf_types (module_name, name, base_type, decl, status, format, units, description, reference) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)", mod.Name, tp.Name, tp.BaseType, tp.Decl, tp.Status, tp.Format, tp.Units, tp.Description, tp.Reference)
+
+		if err != nil {
+			return err
+		}
+
+		err = l.insert_enum_and_ranges("type", tp.Name, &tp)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, nd := range omf_module_nodes(mod) {
+		if nd.Type == nil || nd.Type.Decl != "ImplicitType" {
+			continue
+		}
+
+		err := l.insert_enum_and_ranges("node", nd.Name, nd.Type)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, tb := range mod.Tables {
+		err := l.exec("INSERT INTO omf_tables (module_name, name, entry_name) VALUES (?, ?, ?)", mod.Name, tb.Name, tb.Entry.Name)
+
+		if err != nil {
+			return err
+		}
+
+		for position, col := range tb.Columns {
+			err = l.exec("INSERT INTO omf_columns (module_name, table_name, column_name, position) VALUES (?, ?, ?, ?)", mod.Name, tb.Name, col.Name, position)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		for position, idx := range tb.Indexes {
+			err = l.exec("INSERT INTO omf_indexes (module_name, table_name, index_name, position) VALUES (?, ?, ?, ?)", mod.Name, tb.Name, idx.Name, position)
+
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, nf := range mod.Notifications {
+		err := l.exec("INSERT INTO omf_notifications (module_name, name) VALUES (?, ?)", mod.Name, nf.Name)
+
+		if err != nil {
+			return err
+		}
+
+		for position, obj := range nf.Objects {
+			err = l.exec("INSERT INTO omf_notification_objects (module_name, notification_name, object_name, position) VALUES (?, ?, ?, ?)", mod.Name, nf.Name, obj.Name, position)
+
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (l *sql_loader) upsert_nodes() error {
+	mod := l.module
+
+	var names []any
+
+	var placeholders []string
+
+	for _, nd := range omf_module_nodes(mod) {
+		type_name, type_base_type := "", ""
+
+		if nd.Type != nil {
+			type_name, type_base_type = nd.Type.Name, nd.Type.BaseType
+		}
+
+		content_hash, err := sql_content_hash([]string{nd.NodeHash, nd.Oid, nd.Kind, nd.Decl, nd.Access, nd.Status, nd.Description, type_name, type_base_type})
+
+		if err != nil {
+			return err
+		}
+
+		err = l.exec(`INSERT INTO omf_nodes (module_name, name, node_hash, content_hash, oid, kind, decl, access, status, description, type_name, type_base_type)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (module_name, name) DO UPDATE SET
+	node_hash = excluded.node_hash,
+	content_hash = excluded.content_hash,
+	oid = excluded.oid,
+	kind = excluded.kind,
+	decl = excluded.decl,
+	access = excluded.access,
+	status = excluded.status,
+	description = excluded.description,
+	type_name = excluded.type_name,
+	type_base_type = excluded.type_base_type
+WHERE omf_nodes.content_hash <> excluded.content_hash`,
+			mod.Name, nd.Name, nd.NodeHash, content_hash, nd.Oid, nd.Kind, nd.Decl, nd.Access, nd.Status, nd.Description, type_name, type_base_type)
+
+		if err != nil {
+			return err
+		}
+
+		names = append(names, nd.Name)
+
+		placeholders = append(placeholders, "?")
+	}
+
+	if len(names) == 0 {
+		return l.exec("DELETE FROM omf_nodes WHERE module_name = ?", mod.Name)
+	}
+
+	return l.exec("DELETE FROM omf_nodes WHERE module_name = ? AND name NOT IN ("+strings.Join(placeholders, ", ")+")", append([]any{mod.Name}, names...)...)
+}
+
+func GenerateSQLSchema(dialect OMFSQLDialect) (string, error) {
+	statements, err := sql_schema(dialect)
+
+	if err != nil {
+		return "", err
+	}
+
+	return strings.Join(statements, ";\n\n") + ";\n", nil
+}
+
+func WriteSQLSchema(out io.Writer, dialect OMFSQLDialect) error {
+	schema, err := GenerateSQLSchema(dialect)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(out, schema)
+
+	return err
+}
+
+func CreateSQLSchema(db *sql.DB, dialect OMFSQLDialect) error {
+	statements, err := sql_schema(dialect)
+
+	if err != nil {
+		return err
+	}
+
+	for _, statement := range statements {
+		_, err = db.Exec(statement)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func LoadOmfModuleSQL(db *sql.DB, dialect OMFSQLDialect, mod OMFModule) error {
+	if mod.Name == "" {
+		return errors.New("cannot load an unnamed module")
+	}
+
+	if _, ok := sql_dialect_timestamp[dialect]; !ok {
+		return fmt.Errorf("unsupported sql dialect %q", dialect)
+	}
+
+	tx, err := db.Begin()
+
+	if err != nil {
+		return err
+	}
+
+	content_hash, err := sql_content_hash(mod)
+
+	if err != nil {
+		tx.Rollback()
+
+		return err
+	}
+
+	l := sql_loader{
+		tx:           tx,
+		dialect:      dialect,
+		module:       &mod,
+		content_hash: content_hash,
+	}
+
+	var stored_hash string
+
+	err = tx.QueryRow(sql_rebind(dialect, "SELECT content_hash FROM omf_modules WHERE name = ?"), mod.Name).Scan(&stored_hash)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		err = nil
+	}
+
+	if err == nil && stored_hash == content_hash {
+		return tx.Commit()
+	}
+
+	if err == nil {
+		err = l.upsert_module()
+	}
+
+	if err == nil {
+		err = l.replace_module_children()
+	}
+
+	if err == nil {
+		err = l.upsert_nodes()
+	}
+
+	if err != nil {
+		tx.Rollback()
+
+		return err
+	}
+
+	return tx.Commit()
+}
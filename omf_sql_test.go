@@ -0,0 +1,174 @@
+package omifier
+
+import (
+	"database/sql"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func omf_sqlite_db(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", "file::memory:?_foreign_keys=on")
+
+	if err != nil {
+		t.Fatalf("sql.Open: %s", err)
+	}
+
+	db.SetMaxOpenConns(1)
+
+	t.Cleanup(func() { db.Close() })
+
+	err = CreateSQLSchema(db, OMFSQLDialectSQLite)
+
+	if err != nil {
+		t.Fatalf("CreateSQLSchema: %s", err)
+	}
+
+	return db
+}
+
+func omf_sql_count(t *testing.T, db *sql.DB, query string, args ...any) int {
+	t.Helper()
+
+	var count int
+
+	err := db.QueryRow(query, args...).Scan(&count)
+
+	if err != nil {
+		t.Fatalf("%s: %s", query, err)
+	}
+
+	return count
+}
+
+func TestLoadOmfModuleSQLUpdatesRowsWithUnchangedHashes(t *testing.T) {
+	db := omf_sqlite_db(t)
+
+	mod, err := GetOmfCommomStruct(omf_testdata_path, "ACME-TEST-MIB", false)
+
+	if err != nil {
+		t.Fatalf("GetOmfCommomStruct: %s", err)
+	}
+
+	err = LoadOmfModuleSQL(db, OMFSQLDialectSQLite, mod)
+
+	if err != nil {
+		t.Fatalf("LoadOmfModuleSQL: %s", err)
+	}
+
+	nodes := omf_sql_count(t, db, "SELECT COUNT(*) FROM omf_nodes WHERE module_name = ?", mod.Name)
+
+	if nodes != len(omf_module_nodes(&mod)) {
+		t.Errorf("loaded %d nodes, want %d", nodes, len(omf_module_nodes(&mod)))
+	}
+
+	if omf_sql_count(t, db, "SELECT COUNT(*) FROM omf_notifications WHERE module_name = ?", mod.Name) == 0 {
+		t.Fatalf("ACME-TEST-MIB loaded without notifications")
+	}
+
+	err = LoadOmfModuleSQL(db, OMFSQLDialectSQLite, mod)
+
+	if err != nil {
+		t.Fatalf("reloading LoadOmfModuleSQL: %s", err)
+	}
+
+	if reloaded := omf_sql_count(t, db, "SELECT COUNT(*) FROM omf_nodes WHERE module_name = ?", mod.Name); reloaded != nodes {
+		t.Errorf("reloading the same module left %d nodes, want %d", reloaded, nodes)
+	}
+
+	untouched := mod.Scalars[1].Name
+
+	_, err = db.Exec("UPDATE omf_nodes SET description = 'kept' WHERE module_name = ? AND name = ?", mod.Name, untouched)
+
+	if err != nil {
+		t.Fatalf("UPDATE omf_nodes: %s", err)
+	}
+
+	err = LoadOmfModuleSQL(db, OMFSQLDialectSQLite, mod)
+
+	if err != nil {
+		t.Fatalf("reloading LoadOmfModuleSQL: %s", err)
+	}
+
+	if omf_sql_count(t, db, "SELECT COUNT(*) FROM omf_nodes WHERE module_name = ? AND description = 'kept'", mod.Name) != 1 {
+		t.Errorf("reloading an unchanged module rewrote %s", untouched)
+	}
+
+	changed := mod
+
+	changed.Description = "changed module description"
+
+	changed.Scalars = append([]OMFScalar(nil), mod.Scalars...)
+
+	changed.Scalars[0].Description = "changed scalar description"
+
+	removed := changed.Scalars[len(changed.Scalars)-1].Name
+
+	changed.Scalars = changed.Scalars[:len(changed.Scalars)-1]
+
+	changed.Notifications = nil
+
+	err = LoadOmfModuleSQL(db, OMFSQLDialectSQLite, changed)
+
+	if err != nil {
+		t.Fatalf("LoadOmfModuleSQL of the changed module: %s", err)
+	}
+
+	var description string
+
+	err = db.QueryRow("SELECT description FROM omf_modules WHERE name = ?", mod.Name).Scan(&description)
+
+	if err != nil || description != changed.Description {
+		t.Errorf("module description is %q (%v), want %q", description, err, changed.Description)
+	}
+
+	err = db.QueryRow("SELECT description FROM omf_nodes WHERE module_name = ? AND name = ?", mod.Name, changed.Scalars[0].Name).Scan(&description)
+
+	if err != nil || description != changed.Scalars[0].Description {
+		t.Errorf("%s description is %q (%v), want %q", changed.Scalars[0].Name, description, err, changed.Scalars[0].Description)
+	}
+
+	if omf_sql_count(t, db, "SELECT COUNT(*) FROM omf_nodes WHERE module_name = ? AND name = ? AND description = 'kept'", mod.Name, untouched) != 1 {
+		t.Errorf("%s did not change but its row was rewritten", untouched)
+	}
+
+	if omf_sql_count(t, db, "SELECT COUNT(*) FROM omf_nodes WHERE module_name = ? AND name = ?", mod.Name, removed) != 0 {
+		t.Errorf("%s was removed from the module but is still stored", removed)
+	}
+
+	if omf_sql_count(t, db, "SELECT COUNT(*) FROM omf_notifications WHERE module_name = ?", mod.Name) != 0 {
+		t.Errorf("notifications were removed from the module but are still stored")
+	}
+}
+
+func TestLoadOmfModuleSQLRollsBackOnError(t *testing.T) {
+	db := omf_sqlite_db(t)
+
+	mod, err := GetOmfCommomStruct(omf_testdata_path, "ACME-TEST-MIB", false)
+
+	if err != nil {
+		t.Fatalf("GetOmfCommomStruct: %s", err)
+	}
+
+	_, err = db.Exec("DROP TABLE omf_notification_objects")
+
+	if err != nil {
+		t.Fatalf("DROP TABLE: %s", err)
+	}
+
+	err = LoadOmfModuleSQL(db, OMFSQLDialectSQLite, mod)
+
+	if err == nil {
+		t.Fatalf("LoadOmfModuleSQL succeeded without the omf_notification_objects table")
+	}
+
+	if omf_sql_count(t, db, "SELECT COUNT(*) FROM omf_modules") != 0 {
+		t.Errorf("the failed load left the module stored")
+	}
+
+	if err := LoadOmfModuleSQL(db, "oracle", mod); err == nil {
+		t.Errorf("LoadOmfModuleSQL accepted an unsupported dialect")
+	}
+}
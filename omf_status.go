@@ -0,0 +1,286 @@
+package omifier
+
+import (
+	"fmt"
+	"slices"
+
+	gosmi "github.com/belqlabs/omf-gosmi"
+	"github.com/belqlabs/omf-gosmi/smi"
+	gosmi_types "github.com/belqlabs/omf-gosmi/types"
+)
+
+type OMFStatusIssue struct {
+	Kind             string
+	Module           string
+	Object           string
+	Status           string
+	Relation         string
+	Referenced       string
+	ReferencedStatus string
+	Message          string
+}
+
+type OMFDeprecatedDefinition struct {
+	Name         string
+	Oid          string `json:",omitempty"`
+	Kind         string
+	Status       string
+	ReferencedBy []string `json:",omitempty"`
+}
+
+type OMFStatusReport struct {
+	Module     string
+	Issues     []OMFStatusIssue
+	Deprecated []OMFDeprecatedDefinition
+}
+
+type status_reference struct {
+	from        string
+	from_status string
+	relation    string
+	to          string
+	to_status   string
+}
+
+// SMIv1 statuses rank as RFC 3584 converts them: mandatory is current and
+// optional is obsolete.
+func status_rank(status string) int {
+	switch status {
+	case "Deprecated":
+		return 1
+	case "Obsolete", "Optional":
+		return 2
+	}
+
+	return 0
+}
+
+type status_type struct {
+	name   string
+	decl   string
+	status string
+}
+
+// The named types a node or type derives from, nearest first. They are read
+// from the loaded module, as OMF data only holds a module's own types.
+func read_status_type_chains(module *gosmi.SmiModule) map[string][]status_type {
+	chains := make(map[string][]status_type)
+
+	read_chain := func(tp *gosmi_types.SmiType) []status_type {
+		chain := []status_type{}
+
+		for ; tp != nil; tp = smi.GetParentType(tp) {
+			if tp.Name != "" {
+				chain = append(chain, status_type{string(tp.Name), tp.Decl.String(), tp.Status.String()})
+			}
+		}
+
+		return chain
+	}
+
+	for _, node := range module.GetNodes() {
+		if node.SmiType != nil {
+			chains[node.Name] = read_chain(node.SmiType.GetRaw())
+		}
+	}
+
+	for _, tp := range module.GetTypes() {
+		chains[tp.Name] = read_chain(smi.GetParentType(tp.GetRaw()))
+	}
+
+	return chains
+}
+
+func status_type_relation(decl string) string {
+	if decl == "TextualConvention" {
+		return "TextualConvention"
+	}
+
+	return "Type"
+}
+
+// Without type chains only the type a node names is checked.
+func status_references(mod *OMFModule, chains map[string][]status_type) []status_reference {
+	var references []status_reference
+
+	type_status := make(map[string]string)
+
+	for _, tc := range mod.TextualConventions {
+		type_status[tc.Name] = tc.Status
+	}
+
+	for _, tp := range mod.Types {
+		type_status[tp.Name] = tp.Status
+	}
+
+	node_status := make(map[string]string)
+
+	for _, nd := range omf_module_nodes(mod) {
+		node_status[nd.Name] = nd.Status
+	}
+
+	for _, nd := range omf_module_nodes(mod) {
+		if chain, ok := chains[nd.Name]; ok {
+			for _, tp := range chain {
+				references = append(references, status_reference{nd.Name, nd.Status, status_type_relation(tp.decl), tp.name, tp.status})
+			}
+
+			continue
+		}
+
+		if nd.Type == nil || nd.Type.Name == "" {
+			continue
+		}
+
+		if nd.Type.Decl == "ImplicitType" {
+			if status, ok := type_status[nd.Type.Name]; ok {
+				references = append(references, status_reference{nd.Name, nd.Status, "TextualConvention", nd.Type.Name, status})
+			}
+
+			continue
+		}
+
+		references = append(references, status_reference{nd.Name, nd.Status, status_type_relation(nd.Type.Decl), nd.Type.Name, nd.Type.Status})
+	}
+
+	for _, tp := range mod.Types {
+		for _, parent := range chains[tp.Name] {
+			references = append(references, status_reference{tp.Name, tp.Status, "ParentType", parent.name, parent.status})
+		}
+	}
+
+	for _, tb := range mod.Tables {
+		for _, idx := range tb.Indexes {
+			references = append(references, status_reference{tb.Entry.Name, tb.Entry.Status, "Index", idx.Name, idx.Status})
+		}
+	}
+
+	for _, nf := range mod.Notifications {
+		for _, obj := range nf.Objects {
+			references = append(references, status_reference{nf.Name, nf.Status, "NotificationObject", obj.Name, obj.Status})
+		}
+	}
+
+	for _, gr := range mod.Groups {
+		for _, member := range gr.Members {
+			references = append(references, status_reference{gr.Name, gr.Status, "GroupMember", member.Name, member.Status})
+		}
+	}
+
+	for _, cp := range mod.Compliances {
+		for _, cp_mod := range cp.Modules {
+			if cp_mod.ModName != "" && cp_mod.ModName != mod.Name {
+				continue
+			}
+
+			groups := slices.Clone(cp_mod.MandatoryGroups)
+
+			for _, item := range cp_mod.Groups {
+				groups = append(groups, item.Name)
+			}
+
+			for _, group := range groups {
+				if status, ok := node_status[group]; ok {
+					references = append(references, status_reference{cp.Name, cp.Status, "ComplianceGroup", group, status})
+				}
+			}
+		}
+	}
+
+	return references
+}
+
+// A definition may only reference definitions whose status is at least as
+// current as its own (RFC 2578 section 10.4). A deprecated or obsolete group
+// that still lists current objects is reported separately, since those
+// objects are likely left without a current group.
+func create_status_report(mod *OMFModule, chains map[string][]status_type) OMFStatusReport {
+	report := OMFStatusReport{Module: mod.Name, Issues: []OMFStatusIssue{}, Deprecated: []OMFDeprecatedDefinition{}}
+
+	referenced_by := make(map[string][]string)
+
+	for _, ref := range status_references(mod, chains) {
+		if !slices.Contains(referenced_by[ref.to], ref.from) {
+			referenced_by[ref.to] = append(referenced_by[ref.to], ref.from)
+		}
+
+		issue := OMFStatusIssue{
+			Module:           mod.Name,
+			Object:           ref.from,
+			Status:           ref.from_status,
+			Relation:         ref.relation,
+			Referenced:       ref.to,
+			ReferencedStatus: ref.to_status,
+		}
+
+		switch {
+		case status_rank(ref.to_status) > status_rank(ref.from_status):
+			issue.Kind = "StatusReference"
+
+			issue.Message = fmt.Sprintf("%s %s references %s %s", ref.from_status, ref.from, ref.to_status, ref.to)
+		case ref.relation == "GroupMember" && status_rank(ref.to_status) < status_rank(ref.from_status):
+			issue.Kind = "StaleGroupMember"
+
+			issue.Message = fmt.Sprintf("%s group %s still lists %s object %s", ref.from_status, ref.from, ref.to_status, ref.to)
+		default:
+			continue
+		}
+
+		report.Issues = append(report.Issues, issue)
+	}
+
+	for _, tp := range mod.Types {
+		if status_rank(tp.Status) > 0 {
+			report.Deprecated = append(report.Deprecated, OMFDeprecatedDefinition{
+				Name:         tp.Name,
+				Kind:         tp.Decl,
+				Status:       tp.Status,
+				ReferencedBy: referenced_by[tp.Name],
+			})
+		}
+	}
+
+	for _, nd := range omf_module_nodes(mod) {
+		if status_rank(nd.Status) > 0 {
+			report.Deprecated = append(report.Deprecated, OMFDeprecatedDefinition{
+				Name:         nd.Name,
+				Oid:          nd.Oid,
+				Kind:         nd.Kind,
+				Status:       nd.Status,
+				ReferencedBy: referenced_by[nd.Name],
+			})
+		}
+	}
+
+	return report
+}
+
+func AnalyzeOmfModuleStatus(mod OMFModule) OMFStatusReport {
+	return create_status_report(&mod, nil)
+}
+
+func GetOmfStatusReport(path string, module_name string) (OMFStatusReport, error) {
+	err := init_gosmi(path, module_name)
+
+	if err != nil {
+		exit_gosmi()
+
+		return OMFStatusReport{Module: module_name}, err
+	}
+
+	m, err := gosmi.GetModule(module_name)
+
+	if err != nil {
+		exit_gosmi()
+
+		return OMFStatusReport{Module: module_name}, err
+	}
+
+	mod := omfy_module(&m)
+
+	chains := read_status_type_chains(&m)
+
+	exit_gosmi()
+
+	return create_status_report(&mod, chains), nil
+}
@@ -0,0 +1,106 @@
+package omifier
+
+import (
+	"testing"
+)
+
+func omf_status_issues(report OMFStatusReport) map[string]OMFStatusIssue {
+	issues := make(map[string]OMFStatusIssue)
+
+	for _, issue := range report.Issues {
+		issues[issue.Object+" "+issue.Referenced] = issue
+	}
+
+	return issues
+}
+
+func TestStatusRank(t *testing.T) {
+	tests := []struct {
+		status string
+		rank   int
+	}{
+		{"Current", 0},
+		{"Mandatory", 0},
+		{"Optional", 2},
+		{"Unknown", 0},
+		{"Deprecated", 1},
+		{"Obsolete", 2},
+	}
+
+	for _, tt := range tests {
+		if rank := status_rank(tt.status); rank != tt.rank {
+			t.Errorf("status_rank(%s) = %d, want %d", tt.status, rank, tt.rank)
+		}
+	}
+
+	mod := OMFModule{
+		Name: "STATUS-V1-MIB",
+		Scalars: []OMFScalar{
+			{OMFNode{Name: "statusV1Mandatory", Status: "Mandatory", Type: &OMFType{Name: "StatusV1Optional", Decl: "TypeAssignment", Status: "Optional"}}},
+			{OMFNode{Name: "statusV1Current", Status: "Mandatory", Type: &OMFType{Name: "Integer32", Status: "Current"}}},
+		},
+	}
+
+	report := AnalyzeOmfModuleStatus(mod)
+
+	if len(report.Issues) != 1 || report.Issues[0].Object != "statusV1Mandatory" || report.Issues[0].Referenced != "StatusV1Optional" {
+		t.Errorf("a mandatory object referencing an optional type is reported as %+v", report.Issues)
+	}
+}
+
+func TestStatusFollowsTypeChains(t *testing.T) {
+	mod := OMFModule{
+		Name: "CHAIN-MIB",
+		Types: []OMFType{
+			{Name: "ChainId", Decl: "TextualConvention", Status: "Current"},
+		},
+		Scalars: []OMFScalar{
+			{OMFNode{Name: "chainId", Status: "Current", Type: &OMFType{Name: "ChainId", Decl: "TextualConvention", Status: "Current"}}},
+		},
+	}
+
+	if report := AnalyzeOmfModuleStatus(mod); len(report.Issues) != 0 {
+		t.Fatalf("a current type is reported without its chain: %+v", report.Issues)
+	}
+
+	chains := map[string][]status_type{
+		"chainId": {{"ChainId", "TextualConvention", "Current"}, {"ChainLegacyId", "TextualConvention", "Obsolete"}, {"Integer32", "TypeAssignment", "Unknown"}},
+		"ChainId": {{"ChainLegacyId", "TextualConvention", "Obsolete"}, {"Integer32", "TypeAssignment", "Unknown"}},
+	}
+
+	issues := omf_status_issues(create_status_report(&mod, chains))
+
+	if len(issues) != 2 {
+		t.Errorf("got issues %+v, want chainId and ChainId against ChainLegacyId", issues)
+	}
+
+	if issue := issues["chainId ChainLegacyId"]; issue.Kind != "StatusReference" || issue.Relation != "TextualConvention" || issue.ReferencedStatus != "Obsolete" {
+		t.Errorf("chainId is reported as %+v", issue)
+	}
+
+	if issue := issues["ChainId ChainLegacyId"]; issue.Kind != "StatusReference" || issue.Relation != "ParentType" {
+		t.Errorf("ChainId is reported as %+v", issue)
+	}
+}
+
+func TestStatusReportOfImportedTypes(t *testing.T) {
+	report, err := GetOmfStatusReport(omf_testdata_path, "STATUS-MIB")
+
+	if err != nil {
+		t.Fatalf("GetOmfStatusReport: %s", err)
+	}
+
+	issues := omf_status_issues(report)
+
+	for _, object := range []string{"statusId", "statusRefinedId"} {
+		issue, ok := issues[object+" AcmeLegacyId"]
+
+		if !ok || issue.ReferencedStatus != "Deprecated" || issue.Status != "Current" {
+			t.Errorf("%s against AcmeLegacyId is reported as %+v", object, issue)
+		}
+	}
+
+	if len(report.Issues) != 2 {
+		t.Errorf("got %d issues, want 2: %+v", len(report.Issues), report.Issues)
+	}
+}
@@ -0,0 +1,151 @@
+package omifier
+
+import (
+	"errors"
+	"io"
+
+	"github.com/BurntSushi/toml"
+)
+
+type OMFTelegrafField struct {
+	Name       string `toml:"name"`
+	Oid        string `toml:"oid"`
+	IsTag      bool   `toml:"is_tag,omitempty"`
+	Conversion string `toml:"conversion,omitempty"`
+}
+
+type OMFTelegrafTable struct {
+	Name       string             `toml:"name"`
+	Oid        string             `toml:"oid"`
+	IndexAsTag bool               `toml:"index_as_tag,omitempty"`
+	Fields     []OMFTelegrafField `toml:"field"`
+}
+
+type OMFTelegrafSnmpInput struct {
+	Agents []string           `toml:"agents"`
+	Name   string             `toml:"name"`
+	Fields []OMFTelegrafField `toml:"field,omitempty"`
+	Tables []OMFTelegrafTable `toml:"table,omitempty"`
+}
+
+type OMFTelegrafConfig struct {
+	Inputs struct {
+		Snmp []OMFTelegrafSnmpInput `toml:"snmp"`
+	} `toml:"inputs"`
+}
+
+var telegraf_default_agents = []string{"udp://127.0.0.1:161"}
+
+var telegraf_conversions = map[string]string{
+	"MacAddress":      "hwaddr",
+	"PhysAddress":     "hwaddr",
+	"IpAddress":       "ipaddr",
+	"InetAddressIPv4": "ipaddr",
+	"InetAddressIPv6": "ipaddr",
+}
+
+func telegraf_selected(selected map[string]bool, name string) bool {
+	return len(selected) == 0 || selected[name]
+}
+
+func telegraf_field(nd *OMFNode, instance string, index_like bool) (OMFTelegrafField, bool) {
+	if nd.Access == "NotAccessible" || nd.Access == "Notify" || nd.Type == nil || nd.Type.BaseType == "ObjectIdentifier" {
+		return OMFTelegrafField{}, false
+	}
+
+	field := OMFTelegrafField{
+		Name:       nd.Name,
+		Oid:        "." + nd.Oid + instance,
+		Conversion: telegraf_conversions[nd.Type.Name],
+	}
+
+	field.IsTag = index_like || field.Conversion != "" || nd.Type.BaseType == "OctetString"
+
+	return field, true
+}
+
+func create_telegraf_snmp_input(mod *OMFModule, selected map[string]bool) OMFTelegrafSnmpInput {
+	input := OMFTelegrafSnmpInput{
+		Agents: telegraf_default_agents,
+		Name:   snmp_exporter_module_name(mod.Name),
+	}
+
+	for _, sc := range mod.Scalars {
+		if !telegraf_selected(selected, sc.Name) {
+			continue
+		}
+
+		field, ok := telegraf_field(&sc.OMFNode, ".0", false)
+
+		if ok {
+			input.Fields = append(input.Fields, field)
+		}
+	}
+
+	for _, tb := range mod.Tables {
+		if !telegraf_selected(selected, tb.Name) {
+			continue
+		}
+
+		indexes := make(map[string]bool)
+
+		for _, idx := range tb.Indexes {
+			indexes[idx.Name] = true
+		}
+
+		table := OMFTelegrafTable{
+			Name: tb.Name,
+			Oid:  "." + tb.Oid,
+		}
+
+		for _, col := range tb.Columns {
+			// A not-accessible index can't be walked, so telegraf tags
+			// the rows with their instance instead.
+			if indexes[col.Name] && col.Access == "NotAccessible" {
+				table.IndexAsTag = true
+
+				continue
+			}
+
+			field, ok := telegraf_field(&col, "", indexes[col.Name])
+
+			if ok {
+				table.Fields = append(table.Fields, field)
+			}
+		}
+
+		if len(table.Fields) > 0 {
+			input.Tables = append(input.Tables, table)
+		}
+	}
+
+	return input
+}
+
+func GenerateTelegrafConfig(mod OMFModule, selected ...string) (OMFTelegrafConfig, error) {
+	config := OMFTelegrafConfig{}
+
+	if mod.Name == "" {
+		return config, errors.New("cannot generate a telegraf input for an unnamed module")
+	}
+
+	selected_map := make(map[string]bool)
+
+	for _, name := range selected {
+		selected_map[name] = true
+	}
+
+	config.Inputs.Snmp = append(config.Inputs.Snmp, create_telegraf_snmp_input(&mod, selected_map))
+
+	return config, nil
+}
+
+func WriteTelegrafConfig(out io.Writer, mod OMFModule, selected ...string) error {
+	config, err := GenerateTelegrafConfig(mod, selected...)
+
+	if err != nil {
+		return err
+	}
+
+	return toml.NewEncoder(out).Encode(config)
+}
@@ -0,0 +1,110 @@
+package omifier
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+)
+
+func TestWriteTelegrafConfig(t *testing.T) {
+	mod, err := GetOmfCommomStruct(omf_testdata_path, "ACME-TEST-MIB", false)
+
+	if err != nil {
+		t.Fatalf("GetOmfCommomStruct: %s", err)
+	}
+
+	var out bytes.Buffer
+
+	err = WriteTelegrafConfig(&out, mod, "acmeSystemName", "acmeUptime", "acmePortTable")
+
+	if err != nil {
+		t.Fatalf("WriteTelegrafConfig: %s", err)
+	}
+
+	var config OMFTelegrafConfig
+
+	_, err = toml.Decode(out.String(), &config)
+
+	if err != nil {
+		t.Fatalf("telegraf config does not decode: %s", err)
+	}
+
+	if len(config.Inputs.Snmp) != 1 {
+		t.Fatalf("telegraf config has %d snmp inputs", len(config.Inputs.Snmp))
+	}
+
+	input := config.Inputs.Snmp[0]
+
+	if len(input.Fields) != 2 || len(input.Tables) != 1 {
+		t.Fatalf("telegraf input has %d fields and %d tables, want 2 and 1", len(input.Fields), len(input.Tables))
+	}
+
+	table := input.Tables[0]
+
+	if !table.IndexAsTag {
+		t.Errorf("acmePortTable has a not-accessible index but no index_as_tag")
+	}
+
+	fields := make(map[string]OMFTelegrafField)
+
+	for _, field := range append(input.Fields, table.Fields...) {
+		fields[field.Name] = field
+	}
+
+	tests := []struct {
+		name       string
+		oid        string
+		is_tag     bool
+		conversion string
+	}{
+		{"acmeSystemName", ".1.3.6.1.4.1.99999.1.1.0", true, ""},
+		{"acmeUptime", ".1.3.6.1.4.1.99999.1.2.0", false, ""},
+		{"acmePortName", ".1.3.6.1.4.1.99999.1.10.1.2", true, ""},
+		{"acmePortMac", ".1.3.6.1.4.1.99999.1.10.1.3", true, "hwaddr"},
+		{"acmePortInOctets", ".1.3.6.1.4.1.99999.1.10.1.5", false, ""},
+	}
+
+	for _, tt := range tests {
+		field, ok := fields[tt.name]
+
+		if !ok {
+			t.Errorf("telegraf config has no field %s", tt.name)
+
+			continue
+		}
+
+		if field.Oid != tt.oid || field.IsTag != tt.is_tag || field.Conversion != tt.conversion {
+			t.Errorf("field %s = %+v", tt.name, field)
+		}
+	}
+
+	if _, ok := fields["acmePortIndex"]; ok {
+		t.Errorf("telegraf config walks the not-accessible acmePortIndex")
+	}
+}
+
+func TestTelegrafKeepsAccessibleIndexesAsTags(t *testing.T) {
+	index := OMFNode{Name: "slotNumber", Oid: "1.3.6.1.4.1.77783.1.1.1", Access: "ReadOnly", Type: &OMFType{Name: "Integer32", BaseType: "Integer32"}}
+
+	mod := OMFModule{
+		Name: "SLOT-MIB",
+		Tables: []OMFTable{{
+			OMFNode: OMFNode{Name: "slotTable", Oid: "1.3.6.1.4.1.77783.1"},
+			Indexes: []OMFIndex{{index}},
+			Columns: []OMFNode{index},
+		}},
+	}
+
+	config, err := GenerateTelegrafConfig(mod)
+
+	if err != nil {
+		t.Fatalf("GenerateTelegrafConfig: %s", err)
+	}
+
+	table := config.Inputs.Snmp[0].Tables[0]
+
+	if table.IndexAsTag || len(table.Fields) != 1 || !table.Fields[0].IsTag {
+		t.Errorf("slotTable = %+v, want slotNumber walked as a tag", table)
+	}
+}
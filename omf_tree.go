@@ -0,0 +1,218 @@
+package omifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"iter"
+	"sort"
+	"strconv"
+
+	"github.com/BurntSushi/toml"
+)
+
+type OMFTreeChildren map[string]*OMFTreeNode
+
+func compare_arc_keys(a string, b string) bool {
+	a_num, a_err := strconv.ParseUint(a, 10, 32)
+
+	b_num, b_err := strconv.ParseUint(b, 10, 32)
+
+	if a_err == nil && b_err == nil {
+		return a_num < b_num
+	}
+
+	if a_err == nil {
+		return true
+	}
+
+	if b_err == nil {
+		return false
+	}
+
+	return a < b
+}
+
+func (c OMFTreeChildren) Arcs() []string {
+	arcs := make([]string, 0, len(c))
+
+	for arc := range c {
+		arcs = append(arcs, arc)
+	}
+
+	sort.Slice(arcs, func(i, j int) bool {
+		return compare_arc_keys(arcs[i], arcs[j])
+	})
+
+	return arcs
+}
+
+func (c OMFTreeChildren) Ordered() []*OMFTreeNode {
+	ordered := make([]*OMFTreeNode, 0, len(c))
+
+	for _, arc := range c.Arcs() {
+		ordered = append(ordered, c[arc])
+	}
+
+	return ordered
+}
+
+func (c OMFTreeChildren) All() iter.Seq2[string, *OMFTreeNode] {
+	return func(yield func(string, *OMFTreeNode) bool) {
+		for _, arc := range c.Arcs() {
+			if !yield(arc, c[arc]) {
+				return
+			}
+		}
+	}
+}
+
+func (c OMFTreeChildren) MarshalJSON() ([]byte, error) {
+	if c == nil {
+		return []byte("null"), nil
+	}
+
+	var buf bytes.Buffer
+
+	buf.WriteByte('{')
+
+	for idx, arc := range c.Arcs() {
+		if idx > 0 {
+			buf.WriteByte(',')
+		}
+
+		arc_key, err := json.Marshal(arc)
+
+		if err != nil {
+			return nil, err
+		}
+
+		child, err := json.Marshal(c[arc])
+
+		if err != nil {
+			return nil, err
+		}
+
+		buf.Write(arc_key)
+
+		buf.WriteByte(':')
+
+		buf.Write(child)
+	}
+
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// TOML has no ordered tables, so trees are written with their children as
+// arrays ordered by arc, each child carrying its own arc.
+type omf_toml_tree_node struct {
+	Arc         string `toml:",omitempty"`
+	NodeOid     string
+	Node        OMFNode
+	Synthesized bool                 `toml:",omitempty"`
+	DefinedBy   []string             `toml:",omitempty"`
+	ImportedBy  []string             `toml:",omitempty"`
+	Children    []omf_toml_tree_node `toml:",omitempty"`
+}
+
+func toml_tree_node(arc string, node *OMFTreeNode) omf_toml_tree_node {
+	return omf_toml_tree_node{
+		Arc:         arc,
+		NodeOid:     node.NodeOid,
+		Node:        node.Node,
+		Synthesized: node.Synthesized,
+		DefinedBy:   node.DefinedBy,
+		ImportedBy:  node.ImportedBy,
+		Children:    toml_tree_children(node.Children),
+	}
+}
+
+func toml_tree_children(children OMFTreeChildren) []omf_toml_tree_node {
+	var nodes []omf_toml_tree_node
+
+	for arc, child := range children.All() {
+		nodes = append(nodes, toml_tree_node(arc, child))
+	}
+
+	return nodes
+}
+
+func (t OMFTree) MarshalTOML() ([]byte, error) {
+	ordered := struct {
+		ModuleHash    string
+		ContactInfo   string
+		Description   string
+		Language      string
+		Name          string
+		Organization  string
+		Path          string
+		Reference     string
+		ModuleImports *[]OMFImport `toml:",omitempty"`
+		RootNode      *omf_toml_tree_node
+	}{
+		ModuleHash:    t.ModuleHash,
+		ContactInfo:   t.ContactInfo,
+		Description:   t.Description,
+		Language:      t.Language,
+		Name:          t.Name,
+		Organization:  t.Organization,
+		Path:          t.Path,
+		Reference:     t.Reference,
+		ModuleImports: t.ModuleImports,
+	}
+
+	if t.RootNode != nil {
+		root_node := toml_tree_node("", t.RootNode)
+
+		ordered.RootNode = &root_node
+	}
+
+	return toml.Marshal(ordered)
+}
+
+func (t OMFCompleteModuleTree) MarshalTOML() ([]byte, error) {
+	return toml.Marshal(struct {
+		Hash              string
+		Contact           string
+		Description       string
+		Language          string
+		Name              string
+		Organization      string
+		Path              string
+		Reference         string
+		ConflictPolicy    OMFConflictPolicy     `toml:",omitempty"`
+		Conflicts         []OMFTreeConflict     `toml:",omitempty"`
+		UnresolvedImports []OMFUnresolvedImport `toml:",omitempty"`
+		Tree              []omf_toml_tree_node
+	}{
+		Hash:              t.Hash,
+		Contact:           t.Contact,
+		Description:       t.Description,
+		Language:          t.Language,
+		Name:              t.Name,
+		Organization:      t.Organization,
+		Path:              t.Path,
+		Reference:         t.Reference,
+		ConflictPolicy:    t.ConflictPolicy,
+		Conflicts:         t.Conflicts,
+		UnresolvedImports: t.UnresolvedImports,
+		Tree:              toml_tree_children(t.Tree),
+	})
+}
+
+func (r OMFRegistryTree) MarshalTOML() ([]byte, error) {
+	return toml.Marshal(struct {
+		Paths          []string
+		Modules        []string
+		ConflictPolicy OMFConflictPolicy `toml:",omitempty"`
+		Conflicts      []OMFTreeConflict `toml:",omitempty"`
+		Tree           []omf_toml_tree_node
+	}{
+		Paths:          r.Paths,
+		Modules:        r.Modules,
+		ConflictPolicy: r.ConflictPolicy,
+		Conflicts:      r.Conflicts,
+		Tree:           toml_tree_children(r.Tree),
+	})
+}
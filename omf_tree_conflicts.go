@@ -0,0 +1,196 @@
+package omifier
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	gosmi "github.com/belqlabs/omf-gosmi"
+)
+
+var ErrTreeConflict = errors.New("tree conflict")
+
+type OMFConflictPolicy string
+
+const (
+	OMFConflictKeepFirst           OMFConflictPolicy = "KeepFirst"
+	OMFConflictPreferNewerRevision OMFConflictPolicy = "PreferNewerRevision"
+	OMFConflictError               OMFConflictPolicy = "Error"
+)
+
+// The SMIv1 and SMIv2 base modules both define the top of the internet
+// subtree, so their duplicate definitions are not worth reporting.
+var smi_base_modules = map[string]bool{
+	"RFC1065-SMI": true,
+	"RFC1155-SMI": true,
+	"SNMPv2-SMI":  true,
+}
+
+type OMFTreeConflict struct {
+	Kind           string
+	Oid            string
+	Name           string
+	Module         string
+	ExistingOid    string
+	ExistingName   string
+	ExistingModule string
+	Resolution     string
+}
+
+type tree_placer struct {
+	children   OMFTreeChildren
+	policy     OMFConflictPolicy
+	conflicts  *[]OMFTreeConflict
+	provenance bool
+	names      map[string]OMFNode
+	revisions  map[string]time.Time
+}
+
+func new_tree_placer(children OMFTreeChildren, policy OMFConflictPolicy, conflicts *[]OMFTreeConflict) *tree_placer {
+	if policy == "" {
+		policy = OMFConflictKeepFirst
+	}
+
+	p := &tree_placer{
+		children:  children,
+		policy:    policy,
+		conflicts: conflicts,
+		names:     make(map[string]OMFNode),
+		revisions: make(map[string]time.Time),
+	}
+
+	walk_tree_children(children, 0, &OMFTreeVisitor{Pre: func(node *OMFTreeNode, depth int) error {
+		if _, ok := p.names[node.Node.Name]; !ok && !node.Synthesized {
+			p.names[node.Node.Name] = node.Node
+		}
+
+		return nil
+	}})
+
+	return p
+}
+
+func module_last_updated(m *gosmi.SmiModule) time.Time {
+	revisions := omfy_revisions(m.GetRevisions())
+
+	return read_module_last_updated(parse_module_source(m), &revisions)
+}
+
+func (p *tree_placer) module_revision(module_name string) time.Time {
+	if last_updated, ok := p.revisions[module_name]; ok {
+		return last_updated
+	}
+
+	last_updated := time.Time{}
+
+	m, err := gosmi.GetModule(module_name)
+
+	if err == nil {
+		last_updated = module_last_updated(&m)
+	}
+
+	p.revisions[module_name] = last_updated
+
+	return last_updated
+}
+
+func (p *tree_placer) conflict(kind string, incoming *OMFNode, existing *OMFNode, resolution string) error {
+	conflict := OMFTreeConflict{
+		Kind:           kind,
+		Oid:            incoming.Oid,
+		Name:           incoming.Name,
+		Module:         incoming.Module,
+		ExistingOid:    existing.Oid,
+		ExistingName:   existing.Name,
+		ExistingModule: existing.Module,
+		Resolution:     resolution,
+	}
+
+	*p.conflicts = append(*p.conflicts, conflict)
+
+	if resolution != "Rejected" {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s %s::%s (%s) against %s::%s (%s)", ErrTreeConflict, kind, incoming.Module, incoming.Name, incoming.Oid, existing.Module, existing.Name, existing.Oid)
+}
+
+func (p *tree_placer) place(incoming OMFNode) error {
+	oid, ok := parse_tree_oid(incoming.Oid)
+
+	if !ok {
+		return nil
+	}
+
+	leaf := tree_node_at(oid, 0, p.children, true)
+
+	if leaf == nil {
+		return nil
+	}
+
+	if p.provenance && !slices.Contains(leaf.DefinedBy, incoming.Module) {
+		leaf.DefinedBy = append(leaf.DefinedBy, incoming.Module)
+	}
+
+	if !leaf.Synthesized && leaf.Node.Name == incoming.Name && leaf.Node.Module == incoming.Module {
+		set_tree_node(leaf, incoming)
+
+		return nil
+	}
+
+	existing_name, named := p.names[incoming.Name]
+
+	if named && existing_name.Oid != incoming.Oid {
+		resolution := "KeptBoth"
+
+		if p.policy == OMFConflictError {
+			resolution = "Rejected"
+		}
+
+		err := p.conflict("NameCollision", &incoming, &existing_name, resolution)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if !leaf.Synthesized {
+		existing := leaf.Node
+
+		kind := "OidCollision"
+
+		if existing.Name == incoming.Name {
+			kind = "DuplicateDefinition"
+		}
+
+		if kind == "DuplicateDefinition" && smi_base_modules[existing.Module] && smi_base_modules[incoming.Module] {
+			return nil
+		}
+
+		resolution := "KeptExisting"
+
+		switch {
+		case p.policy == OMFConflictError:
+			resolution = "Rejected"
+		case p.policy == OMFConflictPreferNewerRevision && p.module_revision(incoming.Module).After(p.module_revision(existing.Module)):
+			resolution = "Replaced"
+		}
+
+		err := p.conflict(kind, &incoming, &existing, resolution)
+
+		if err != nil || resolution != "Replaced" {
+			return err
+		}
+
+		if replaced, ok := p.names[existing.Name]; ok && replaced.Oid == existing.Oid && replaced.Module == existing.Module {
+			delete(p.names, existing.Name)
+		}
+	}
+
+	set_tree_node(leaf, incoming)
+
+	p.names[incoming.Name] = leaf.Node
+
+	return nil
+}
@@ -0,0 +1,162 @@
+package omifier
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func omf_conflict_kinds(conflicts []OMFTreeConflict) map[string]OMFTreeConflict {
+	kinds := make(map[string]OMFTreeConflict)
+
+	for _, conflict := range conflicts {
+		kinds[conflict.Kind] = conflict
+	}
+
+	return kinds
+}
+
+func TestTreeConflictsAreReported(t *testing.T) {
+	registry := NewOmfRegistryTree(OMFConflictKeepFirst, omf_testdata_path)
+
+	for _, module_name := range []string{"ACME-TEST-MIB", "CLASH-MIB"} {
+		err := registry.AddModule(module_name)
+
+		if err != nil {
+			t.Fatalf("AddModule(%s): %s", module_name, err)
+		}
+	}
+
+	kinds := omf_conflict_kinds(registry.Conflicts)
+
+	tests := []struct {
+		kind          string
+		name          string
+		existing_name string
+		resolution    string
+	}{
+		{"OidCollision", "clashRoot", "acmeTestMIB", "KeptExisting"},
+		{"DuplicateDefinition", "acmeObjects", "acmeObjects", "KeptExisting"},
+		{"NameCollision", "acmeUptime", "acmeUptime", "KeptBoth"},
+	}
+
+	for _, tt := range tests {
+		conflict, ok := kinds[tt.kind]
+
+		if !ok {
+			t.Errorf("no %s reported in %+v", tt.kind, registry.Conflicts)
+
+			continue
+		}
+
+		if conflict.Name != tt.name || conflict.ExistingName != tt.existing_name || conflict.Module != "CLASH-MIB" || conflict.ExistingModule != "ACME-TEST-MIB" || conflict.Resolution != tt.resolution {
+			t.Errorf("%s reported as %+v", tt.kind, conflict)
+		}
+	}
+
+	if node := registry.Find("1.3.6.1.4.1.99999"); node == nil || node.Node.Name != "acmeTestMIB" {
+		t.Errorf("KeepFirst replaced acmeTestMIB with %+v", node)
+	}
+}
+
+func TestTreeConflictErrorPolicy(t *testing.T) {
+	registry := NewOmfRegistryTree(OMFConflictError, omf_testdata_path)
+
+	err := registry.AddModule("ACME-TEST-MIB")
+
+	if err != nil {
+		t.Fatalf("AddModule(ACME-TEST-MIB): %s", err)
+	}
+
+	err = registry.AddModule("CLASH-MIB")
+
+	if !errors.Is(err, ErrTreeConflict) {
+		t.Fatalf("AddModule(CLASH-MIB) = %v, want ErrTreeConflict", err)
+	}
+
+	if slices.Contains(registry.Modules, "CLASH-MIB") {
+		t.Errorf("a rejected module was registered: %v", registry.Modules)
+	}
+}
+
+func TestTreeConflictsSkipBaseModuleDuplicates(t *testing.T) {
+	registry := NewOmfRegistryTree(OMFConflictError, omf_testdata_path)
+
+	for _, module_name := range []string{"ACME-TEST-MIB", "RFC1155-SMI"} {
+		err := registry.AddModule(module_name)
+
+		if err != nil {
+			t.Fatalf("AddModule(%s): %s", module_name, err)
+		}
+	}
+
+	if len(registry.Conflicts) != 0 {
+		t.Errorf("SNMPv2-SMI and RFC1155-SMI reported %+v", registry.Conflicts)
+	}
+
+	node := registry.Find("1.3.6.1.4.1")
+
+	if node == nil || node.Node.Module != "SNMPv2-SMI" || !slices.Equal(node.DefinedBy, []string{"SNMPv2-SMI", "RFC1155-SMI"}) {
+		t.Errorf("enterprises is %+v", node)
+	}
+}
+
+func TestTreeConflictErrorPolicyRejectsDuplicates(t *testing.T) {
+	var conflicts []OMFTreeConflict
+
+	placer := new_tree_placer(make(OMFTreeChildren), OMFConflictError, &conflicts)
+
+	err := placer.place(OMFNode{Name: "dupRoot", Oid: "1.3.6.1.4.1.77790", Module: "DUP-A-MIB"})
+
+	if err != nil {
+		t.Fatalf("placing dupRoot: %s", err)
+	}
+
+	err = placer.place(OMFNode{Name: "dupRoot", Oid: "1.3.6.1.4.1.77790", Module: "DUP-B-MIB"})
+
+	if !errors.Is(err, ErrTreeConflict) {
+		t.Fatalf("placing a duplicate definition = %v, want ErrTreeConflict", err)
+	}
+
+	if len(conflicts) != 1 || conflicts[0].Kind != "DuplicateDefinition" || conflicts[0].Resolution != "Rejected" {
+		t.Errorf("conflicts = %+v, want a rejected DuplicateDefinition", conflicts)
+	}
+}
+
+func TestTreeConflictNamesFollowReplacedNodes(t *testing.T) {
+	var conflicts []OMFTreeConflict
+
+	placer := new_tree_placer(make(OMFTreeChildren), OMFConflictPreferNewerRevision, &conflicts)
+
+	placer.revisions["OLD-MIB"] = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	placer.revisions["NEW-MIB"] = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, nd := range []OMFNode{
+		{Name: "sharedName", Oid: "1.3.6.1.4.1.77791", Module: "OLD-MIB"},
+		{Name: "oldOnlyName", Oid: "1.3.6.1.4.1.77792", Module: "OLD-MIB"},
+		{Name: "sharedName", Oid: "1.3.6.1.4.1.77791", Module: "NEW-MIB"},
+		{Name: "newOnlyName", Oid: "1.3.6.1.4.1.77792", Module: "NEW-MIB"},
+		{Name: "sharedName", Oid: "1.3.6.1.4.1.77793", Module: "OTHER-MIB"},
+		{Name: "oldOnlyName", Oid: "1.3.6.1.4.1.77794", Module: "OTHER-MIB"},
+	} {
+		err := placer.place(nd)
+
+		if err != nil {
+			t.Fatalf("placing %s::%s: %s", nd.Module, nd.Name, err)
+		}
+	}
+
+	var collisions []OMFTreeConflict
+
+	for _, conflict := range conflicts {
+		if conflict.Kind == "NameCollision" {
+			collisions = append(collisions, conflict)
+		}
+	}
+
+	if len(collisions) != 1 || collisions[0].Name != "sharedName" || collisions[0].ExistingModule != "NEW-MIB" {
+		t.Errorf("name collisions = %+v, want sharedName against NEW-MIB only", collisions)
+	}
+}
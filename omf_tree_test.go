@@ -0,0 +1,123 @@
+package omifier
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+)
+
+func TestTreeChildrenArcOrder(t *testing.T) {
+	children := OMFTreeChildren{
+		"10": {NodeOid: "1.10"},
+		"2":  {NodeOid: "1.2"},
+		"1":  {NodeOid: "1.1"},
+		"30": {NodeOid: "1.30"},
+		"3":  {NodeOid: "1.3"},
+	}
+
+	want := []string{"1", "2", "3", "10", "30"}
+
+	if arcs := children.Arcs(); !slices.Equal(arcs, want) {
+		t.Errorf("Arcs() = %v, want %v", arcs, want)
+	}
+
+	var iterated []string
+
+	for arc, child := range children.All() {
+		if child.NodeOid != "1."+arc {
+			t.Errorf("All() yielded %s for arc %s", child.NodeOid, arc)
+		}
+
+		iterated = append(iterated, arc)
+	}
+
+	if !slices.Equal(iterated, want) {
+		t.Errorf("All() = %v, want %v", iterated, want)
+	}
+
+	encoded, err := json.Marshal(children)
+
+	if err != nil {
+		t.Fatalf("json.Marshal: %s", err)
+	}
+
+	if !strings.HasPrefix(string(encoded), `{"1":`) || strings.Index(string(encoded), `"3":`) > strings.Index(string(encoded), `"10":`) {
+		t.Errorf("json.Marshal wrote children out of arc order: %s", encoded)
+	}
+}
+
+func omf_assert_oid_order(t *testing.T, format string, encoded string, oids ...string) {
+	t.Helper()
+
+	last := -1
+
+	for _, oid := range oids {
+		idx := strings.Index(encoded, `"`+oid+`"`)
+
+		if idx < 0 {
+			t.Errorf("%s output has no node %s", format, oid)
+
+			return
+		}
+
+		if idx < last {
+			t.Errorf("%s output writes %s out of arc order", format, oid)
+		}
+
+		last = idx
+	}
+}
+
+func TestTreeSerializationKeepsArcOrder(t *testing.T) {
+	tree, err := GetOmfModuleTree(omf_testdata_path, "ACME-TEST-MIB")
+
+	if err != nil {
+		t.Fatalf("GetOmfModuleTree: %s", err)
+	}
+
+	oids := []string{
+		"1.3.6.1.4.1.99999.1.1",
+		"1.3.6.1.4.1.99999.1.2",
+		"1.3.6.1.4.1.99999.1.3",
+		"1.3.6.1.4.1.99999.1.10",
+	}
+
+	encoded_json, err := json.Marshal(tree)
+
+	if err != nil {
+		t.Fatalf("json.Marshal: %s", err)
+	}
+
+	omf_assert_oid_order(t, "JSON", string(encoded_json), oids...)
+
+	encoded_toml, err := toml.Marshal(tree)
+
+	if err != nil {
+		t.Fatalf("toml.Marshal: %s", err)
+	}
+
+	omf_assert_oid_order(t, "TOML", string(encoded_toml), oids...)
+
+	var decoded map[string]any
+
+	if _, err := toml.Decode(string(encoded_toml), &decoded); err != nil {
+		t.Fatalf("toml.Decode of the marshaled tree: %s", err)
+	}
+
+	complete_tree, err := CreateCompleteTreeFromModule(omf_testdata_path, "ACME-TEST-MIB")
+
+	if err != nil {
+		t.Fatalf("CreateCompleteTreeFromModule: %s", err)
+	}
+
+	encoded_toml, err = toml.Marshal(complete_tree)
+
+	if err != nil {
+		t.Fatalf("toml.Marshal: %s", err)
+	}
+
+	omf_assert_oid_order(t, "TOML", string(encoded_toml), oids...)
+}
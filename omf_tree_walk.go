@@ -0,0 +1,249 @@
+package omifier
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	gosmi_types "github.com/belqlabs/omf-gosmi/types"
+)
+
+var ErrSkipSubtree = errors.New("skip subtree")
+
+var ErrStopWalk = errors.New("stop walk")
+
+var ErrOidNotInTree = errors.New("oid not in tree")
+
+type OMFTreeVisitFunc func(node *OMFTreeNode, depth int) error
+
+type OMFTreeVisitor struct {
+	Pre      OMFTreeVisitFunc
+	Post     OMFTreeVisitFunc
+	MinDepth int
+	MaxDepth int
+	Kinds    []string
+}
+
+func split_oid_arcs(oid string) []string {
+	oid = strings.Trim(oid, ".")
+
+	if oid == "" {
+		return []string{}
+	}
+
+	return strings.Split(oid, ".")
+}
+
+func parse_tree_oid(oid string) (gosmi_types.Oid, bool) {
+	if len(split_oid_arcs(oid)) == 0 {
+		return nil, false
+	}
+
+	parsed_oid, err := gosmi_types.OidFromString(oid)
+
+	return parsed_oid, err == nil
+}
+
+// Follows oid from its arc at index from down the children maps. With create
+// set, missing arcs are filled with synthesized nodes, which is how every
+// tree is built; without it a missing arc ends the lookup.
+func tree_node_at(oid gosmi_types.Oid, from int, children OMFTreeChildren, create bool) *OMFTreeNode {
+	var found *OMFTreeNode
+
+	for idx := from; idx < len(oid); idx++ {
+		if children == nil {
+			if !create || found == nil {
+				return nil
+			}
+
+			found.Children = make(OMFTreeChildren)
+
+			children = found.Children
+		}
+
+		arc := oid_arc_key(oid[idx])
+
+		next, ok := children[arc]
+
+		if !ok {
+			if !create {
+				return nil
+			}
+
+			next = synthesize_tree_node(oid[:idx+1])
+
+			children[arc] = next
+		}
+
+		found = next
+
+		children = next.Children
+	}
+
+	return found
+}
+
+func (v *OMFTreeVisitor) accepts(node *OMFTreeNode, depth int) bool {
+	if depth < v.MinDepth {
+		return false
+	}
+
+	if v.MaxDepth > 0 && depth > v.MaxDepth {
+		return false
+	}
+
+	if len(v.Kinds) == 0 {
+		return true
+	}
+
+	return slices.Contains(v.Kinds, node.Node.Kind)
+}
+
+func walk_tree_node(node *OMFTreeNode, depth int, visitor *OMFTreeVisitor) error {
+	if node == nil {
+		return nil
+	}
+
+	accepted := visitor.accepts(node, depth)
+
+	if accepted && visitor.Pre != nil {
+		err := visitor.Pre(node, depth)
+
+		if errors.Is(err, ErrSkipSubtree) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if visitor.MaxDepth <= 0 || depth < visitor.MaxDepth {
+		err := walk_tree_children(node.Children, depth+1, visitor)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if accepted && visitor.Post != nil {
+		err := visitor.Post(node, depth)
+
+		if errors.Is(err, ErrSkipSubtree) {
+			return nil
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func walk_tree_children(children OMFTreeChildren, depth int, visitor *OMFTreeVisitor) error {
+	for _, child := range children.All() {
+		err := walk_tree_node(child, depth, visitor)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func finish_walk(err error) error {
+	if errors.Is(err, ErrStopWalk) {
+		return nil
+	}
+
+	return err
+}
+
+func WalkTreeNode(node *OMFTreeNode, visitor OMFTreeVisitor) error {
+	return finish_walk(walk_tree_node(node, 0, &visitor))
+}
+
+func walk_tree_prefix(prefix_node *OMFTreeNode, depth int, oid string, visitor *OMFTreeVisitor) error {
+	if prefix_node == nil {
+		return fmt.Errorf("%w: %s", ErrOidNotInTree, oid)
+	}
+
+	return finish_walk(walk_tree_node(prefix_node, depth, visitor))
+}
+
+func (t OMFTree) Find(oid string) *OMFTreeNode {
+	if t.RootNode == nil {
+		return nil
+	}
+
+	parsed_oid, ok := parse_tree_oid(oid)
+
+	if !ok {
+		return nil
+	}
+
+	root_oid, ok := parse_tree_oid(t.RootNode.NodeOid)
+
+	if !ok || !parsed_oid.ChildOf(root_oid) {
+		return nil
+	}
+
+	if len(parsed_oid) == len(root_oid) {
+		return t.RootNode
+	}
+
+	return tree_node_at(parsed_oid, len(root_oid), t.RootNode.Children, false)
+}
+
+func (t OMFTree) Walk(visitor OMFTreeVisitor) error {
+	return WalkTreeNode(t.RootNode, visitor)
+}
+
+// Depths are counted from RootNode, as in Walk.
+func (t OMFTree) WalkPrefix(oid string, visitor OMFTreeVisitor) error {
+	prefix_node := t.Find(oid)
+
+	depth := 0
+
+	if prefix_node != nil {
+		depth = len(split_oid_arcs(prefix_node.NodeOid)) - len(split_oid_arcs(t.RootNode.NodeOid))
+	}
+
+	return walk_tree_prefix(prefix_node, depth, oid, &visitor)
+}
+
+func find_tree_node(oid string, children OMFTreeChildren) *OMFTreeNode {
+	parsed_oid, ok := parse_tree_oid(oid)
+
+	if !ok {
+		return nil
+	}
+
+	return tree_node_at(parsed_oid, 0, children, false)
+}
+
+func walk_tree_children_prefix(oid string, children OMFTreeChildren, visitor *OMFTreeVisitor) error {
+	prefix_node := find_tree_node(oid, children)
+
+	depth := 0
+
+	if prefix_node != nil {
+		depth = len(split_oid_arcs(prefix_node.NodeOid)) - 1
+	}
+
+	return walk_tree_prefix(prefix_node, depth, oid, visitor)
+}
+
+func (t OMFCompleteModuleTree) Find(oid string) *OMFTreeNode {
+	return find_tree_node(oid, t.Tree)
+}
+
+func (t OMFCompleteModuleTree) Walk(visitor OMFTreeVisitor) error {
+	return finish_walk(walk_tree_children(t.Tree, 0, &visitor))
+}
+
+// Depths are counted from the top level arcs, as in Walk.
+func (t OMFCompleteModuleTree) WalkPrefix(oid string, visitor OMFTreeVisitor) error {
+	return walk_tree_children_prefix(oid, t.Tree, &visitor)
+}
@@ -0,0 +1,160 @@
+package omifier
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+const omf_acme_port_entry_oid = "1.3.6.1.4.1.99999.1.10.1"
+
+func omf_walk_names(t *testing.T, walk func(OMFTreeVisitor) error, visitor OMFTreeVisitor) ([]string, map[string]int) {
+	t.Helper()
+
+	var names []string
+
+	depths := make(map[string]int)
+
+	visit := func(node *OMFTreeNode, depth int) error {
+		names = append(names, node.Node.Name)
+
+		depths[node.Node.Name] = depth
+
+		return nil
+	}
+
+	if visitor.Post != nil {
+		visitor.Post = visit
+	} else {
+		visitor.Pre = visit
+	}
+
+	err := walk(visitor)
+
+	if err != nil {
+		t.Fatalf("walk: %s", err)
+	}
+
+	return names, depths
+}
+
+func TestTreeWalkPrefixKeepsDepths(t *testing.T) {
+	tree, err := GetOmfModuleTree(omf_testdata_path, "ACME-TEST-MIB")
+
+	if err != nil {
+		t.Fatalf("GetOmfModuleTree: %s", err)
+	}
+
+	complete_tree, err := CreateCompleteTreeFromModule(omf_testdata_path, "ACME-TEST-MIB")
+
+	if err != nil {
+		t.Fatalf("CreateCompleteTreeFromModule: %s", err)
+	}
+
+	tests := []struct {
+		name        string
+		walk        func(OMFTreeVisitor) error
+		walk_prefix func(string, OMFTreeVisitor) error
+	}{
+		{"module tree", tree.Walk, tree.WalkPrefix},
+		{"complete tree", complete_tree.Walk, complete_tree.WalkPrefix},
+	}
+
+	for _, tt := range tests {
+		_, walk_depths := omf_walk_names(t, tt.walk, OMFTreeVisitor{})
+
+		names, prefix_depths := omf_walk_names(t, func(v OMFTreeVisitor) error { return tt.walk_prefix(omf_acme_port_entry_oid, v) }, OMFTreeVisitor{})
+
+		want := []string{"acmePortEntry", "acmePortIndex", "acmePortName", "acmePortMac", "acmePortState", "acmePortInOctets", "acmePortRowStatus"}
+
+		if !slices.Equal(names, want) {
+			t.Errorf("%s: WalkPrefix visited %v, want %v", tt.name, names, want)
+		}
+
+		for name, depth := range prefix_depths {
+			if walk_depths[name] != depth {
+				t.Errorf("%s: %s is at depth %d in WalkPrefix and %d in Walk", tt.name, name, depth, walk_depths[name])
+			}
+		}
+
+		entry_depth := walk_depths["acmePortEntry"]
+
+		names, _ = omf_walk_names(t, func(v OMFTreeVisitor) error { return tt.walk_prefix(omf_acme_port_entry_oid, v) }, OMFTreeVisitor{MinDepth: entry_depth + 1, MaxDepth: entry_depth + 1})
+
+		if len(names) != len(want)-1 || slices.Contains(names, "acmePortEntry") {
+			t.Errorf("%s: WalkPrefix with depth limits visited %v", tt.name, names)
+		}
+
+		names, _ = omf_walk_names(t, func(v OMFTreeVisitor) error { return tt.walk_prefix(omf_acme_port_entry_oid, v) }, OMFTreeVisitor{MaxDepth: entry_depth - 1})
+
+		if len(names) != 0 {
+			t.Errorf("%s: WalkPrefix below MaxDepth visited %v", tt.name, names)
+		}
+	}
+}
+
+func TestTreeWalkVisitorControl(t *testing.T) {
+	tree, err := CreateCompleteTreeFromModule(omf_testdata_path, "ACME-TEST-MIB")
+
+	if err != nil {
+		t.Fatalf("CreateCompleteTreeFromModule: %s", err)
+	}
+
+	walk_entry := func(v OMFTreeVisitor) error { return tree.WalkPrefix(omf_acme_port_entry_oid, v) }
+
+	names, _ := omf_walk_names(t, walk_entry, OMFTreeVisitor{Post: func(*OMFTreeNode, int) error { return nil }})
+
+	if names[len(names)-1] != "acmePortEntry" {
+		t.Errorf("post order walk ended with %s, want acmePortEntry", names[len(names)-1])
+	}
+
+	names, _ = omf_walk_names(t, walk_entry, OMFTreeVisitor{Kinds: []string{"Column"}})
+
+	if len(names) != 6 || slices.Contains(names, "acmePortEntry") {
+		t.Errorf("Kinds filter visited %v", names)
+	}
+
+	visited := 0
+
+	err = tree.Walk(OMFTreeVisitor{Pre: func(node *OMFTreeNode, depth int) error {
+		visited++
+
+		return ErrStopWalk
+	}})
+
+	if err != nil || visited != 1 {
+		t.Errorf("ErrStopWalk: walk returned %v after %d nodes", err, visited)
+	}
+
+	var skipped []string
+
+	err = tree.WalkPrefix("1.3.6.1.4.1.99999.1", OMFTreeVisitor{Pre: func(node *OMFTreeNode, depth int) error {
+		skipped = append(skipped, node.Node.Name)
+
+		if node.Node.Name == "acmePortTable" {
+			return ErrSkipSubtree
+		}
+
+		return nil
+	}})
+
+	if err != nil || slices.Contains(skipped, "acmePortEntry") || !slices.Contains(skipped, "acmePortTable") {
+		t.Errorf("ErrSkipSubtree: walk returned %v and visited %v", err, skipped)
+	}
+}
+
+func TestTreeWalkPrefixMissingOid(t *testing.T) {
+	tree, err := CreateCompleteTreeFromModule(omf_testdata_path, "ACME-TEST-MIB")
+
+	if err != nil {
+		t.Fatalf("CreateCompleteTreeFromModule: %s", err)
+	}
+
+	for _, oid := range []string{"1.3.6.1.4.1.99999.1.99", "", "not.an.oid"} {
+		err := tree.WalkPrefix(oid, OMFTreeVisitor{})
+
+		if !errors.Is(err, ErrOidNotInTree) {
+			t.Errorf("WalkPrefix(%q) = %v, want ErrOidNotInTree", oid, err)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package omifier
+
+import (
+	gosmi_types "github.com/belqlabs/omf-gosmi/types"
+)
+
+type omf_well_known_node struct {
+	Name   string
+	Module string
+}
+
+var omf_well_known_nodes = map[string]omf_well_known_node{
+	"0":              {Name: "ccitt"},
+	"0.0":            {Name: "zeroDotZero", Module: "SNMPv2-SMI"},
+	"1":              {Name: "iso"},
+	"1.3":            {Name: "org", Module: "SNMPv2-SMI"},
+	"1.3.6":          {Name: "dod", Module: "SNMPv2-SMI"},
+	"1.3.6.1":        {Name: "internet", Module: "SNMPv2-SMI"},
+	"1.3.6.1.1":      {Name: "directory", Module: "SNMPv2-SMI"},
+	"1.3.6.1.2":      {Name: "mgmt", Module: "SNMPv2-SMI"},
+	"1.3.6.1.2.1":    {Name: "mib-2", Module: "SNMPv2-SMI"},
+	"1.3.6.1.2.1.1":  {Name: "system", Module: "SNMPv2-MIB"},
+	"1.3.6.1.2.1.2":  {Name: "interfaces", Module: "IF-MIB"},
+	"1.3.6.1.2.1.10": {Name: "transmission", Module: "SNMPv2-SMI"},
+	"1.3.6.1.2.1.11": {Name: "snmp", Module: "SNMPv2-MIB"},
+	"1.3.6.1.2.1.31": {Name: "ifMIB", Module: "IF-MIB"},
+	"1.3.6.1.3":      {Name: "experimental", Module: "SNMPv2-SMI"},
+	"1.3.6.1.4":      {Name: "private", Module: "SNMPv2-SMI"},
+	"1.3.6.1.4.1":    {Name: "enterprises", Module: "SNMPv2-SMI"},
+	"1.3.6.1.5":      {Name: "security", Module: "SNMPv2-SMI"},
+	"1.3.6.1.6":      {Name: "snmpV2", Module: "SNMPv2-SMI"},
+	"1.3.6.1.6.1":    {Name: "snmpDomains", Module: "SNMPv2-SMI"},
+	"1.3.6.1.6.2":    {Name: "snmpProxys", Module: "SNMPv2-SMI"},
+	"1.3.6.1.6.3":    {Name: "snmpModules", Module: "SNMPv2-SMI"},
+	"2":              {Name: "joint-iso-ccitt"},
+}
+
+func synthesize_omf_node(oid gosmi_types.Oid) OMFNode {
+	oid_str := oid.String()
+
+	omf_node := OMFNode{
+		Kind: gosmi_types.NodeNode.String(),
+		Name: oid_str,
+		Oid:  oid_str,
+	}
+
+	if well_known, ok := omf_well_known_nodes[oid_str]; ok {
+		omf_node.Name = well_known.Name
+
+		omf_node.Module = well_known.Module
+	}
+
+	omf_node.NodeHash = generate_node_hash(&omf_node)
+
+	return omf_node
+}
+
+func synthesize_tree_node(oid gosmi_types.Oid) *OMFTreeNode {
+	omf_node := synthesize_omf_node(oid)
+
+	return &OMFTreeNode{
+		NodeOid:     omf_node.Oid,
+		Node:        omf_node,
+		Synthesized: true,
+		Children:    make(OMFTreeChildren),
+	}
+}
@@ -0,0 +1,604 @@
+package omifier
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var yang_format_string_regex = regexp.MustCompile(`^[0-9]+a$`)
+
+var yang_imports = map[string]string{
+	"ietf-yang-smiv2": "smiv2",
+	"ietf-yang-types": "yang",
+	"ietf-inet-types": "inet",
+}
+
+var yang_base_types = map[string]string{
+	"Integer32":        "int32",
+	"Integer64":        "int64",
+	"Unsigned32":       "uint32",
+	"Unsigned64":       "uint64",
+	"OctetString":      "binary",
+	"ObjectIdentifier": "yang:object-identifier-128",
+	"Counter32":        "yang:counter32",
+	"Counter64":        "yang:counter64",
+	"Gauge32":          "yang:gauge32",
+	"TimeTicks":        "yang:timeticks",
+	"IpAddress":        "inet:ipv4-address",
+	"Opaque":           "binary",
+}
+
+var yang_well_known_types = map[string]string{
+	"SNMPv2-TC::DisplayString": "string",
+	"SNMPv2-TC::PhysAddress":   "yang:phys-address",
+	"SNMPv2-TC::MacAddress":    "yang:mac-address",
+	"SNMPv2-TC::TruthValue":    "boolean",
+	"SNMPv2-TC::TimeStamp":     "yang:timestamp",
+}
+
+var yang_prefix_modules = map[string]string{
+	"yang": "ietf-yang-types",
+	"inet": "ietf-inet-types",
+}
+
+type yang_writer struct {
+	module      *OMFModule
+	prefix      string
+	body        strings.Builder
+	indent      int
+	required    map[string]bool
+	type_owners map[string]string
+	leaf_paths  map[string]string
+	leaf_keys   map[string][]string
+	oid_names   map[string]string
+}
+
+func yang_prefix(module_name string) string {
+	return strings.ToLower(module_name)
+}
+
+func yang_quote(text string) string {
+	text = strings.ReplaceAll(text, "\\", "\\\\")
+
+	return "\"" + strings.ReplaceAll(text, "\"", "\\\"") + "\""
+}
+
+func yang_status(status string) string {
+	switch status {
+	case "Deprecated":
+		return "deprecated"
+	case "Obsolete", "Optional":
+		return "obsolete"
+	}
+
+	return ""
+}
+
+func new_yang_writer(mod *OMFModule) *yang_writer {
+	w := &yang_writer{
+		module:      mod,
+		prefix:      yang_prefix(mod.Name),
+		required:    make(map[string]bool),
+		type_owners: make(map[string]string),
+		leaf_paths:  make(map[string]string),
+		leaf_keys:   make(map[string][]string),
+		oid_names:   omf_module_oid_names(mod),
+	}
+
+	for _, imp := range mod.Imports {
+		for _, name := range imp.ImportedNodes {
+			w.type_owners[name] = imp.ModName
+		}
+	}
+
+	for _, tp := range mod.Types {
+		w.type_owners[tp.Name] = mod.Name
+	}
+
+	root := "/" + w.prefix + ":" + mod.Name
+
+	for _, sc := range mod.Scalars {
+		w.leaf_paths[sc.Name] = root + "/" + w.prefix + ":" + w.scalar_container(&sc.OMFNode) + "/" + w.prefix + ":" + sc.Name
+	}
+
+	for _, tb := range mod.Tables {
+		for _, col := range tb.Columns {
+			w.leaf_paths[col.Name] = fmt.Sprintf("%s/%s:%s/%s:%s/%s:%s", root, w.prefix, tb.Name, w.prefix, tb.Entry.Name, w.prefix, col.Name)
+
+			for _, idx := range tb.Indexes {
+				w.leaf_keys[col.Name] = append(w.leaf_keys[col.Name], idx.Name)
+			}
+		}
+	}
+
+	return w
+}
+
+func (w *yang_writer) require(module string) string {
+	if prefix, ok := yang_imports[module]; ok {
+		w.required[module] = true
+
+		return prefix
+	}
+
+	if module == "" || module == w.module.Name {
+		return ""
+	}
+
+	w.required[module] = true
+
+	return yang_prefix(module)
+}
+
+func (w *yang_writer) printf(format string, args ...any) {
+	fmt.Fprintf(&w.body, strings.Repeat("  ", w.indent)+format+"\n", args...)
+}
+
+func (w *yang_writer) open(format string, args ...any) {
+	w.printf(format+" {", args...)
+
+	w.indent++
+}
+
+func (w *yang_writer) close() {
+	w.indent--
+
+	w.printf("}")
+}
+
+func (w *yang_writer) scalar_container(nd *OMFNode) string {
+	name, _ := omf_scalar_parent(w.oid_names, nd)
+
+	return name
+}
+
+func (w *yang_writer) base_type(tp *OMFType) string {
+	name := tp.Name
+
+	if tp.Decl == "ImplicitType" || name == "" {
+		name = tp.BaseType
+	}
+
+	if tp.BaseType == "OctetString" && yang_format_string_regex.MatchString(tp.Format) {
+		return "string"
+	}
+
+	yang_type, ok := yang_base_types[name]
+
+	if !ok {
+		yang_type, ok = yang_base_types[tp.BaseType]
+	}
+
+	if !ok {
+		return "binary"
+	}
+
+	if prefix, _, found := strings.Cut(yang_type, ":"); found {
+		w.require(yang_prefix_modules[prefix])
+	}
+
+	return yang_type
+}
+
+func (w *yang_writer) write_restrictions(tp *OMFType, yang_type string) {
+	switch tp.BaseType {
+	case "Enum":
+		w.open("type enumeration")
+
+		for _, name := range smi_ordered_enum(tp.Enum) {
+			w.open("enum %s", name)
+
+			w.printf("value %d;", tp.Enum[name])
+
+			w.close()
+		}
+
+		w.close()
+
+		return
+	case "Bits":
+		w.open("type bits")
+
+		for _, name := range smi_ordered_enum(tp.Enum) {
+			w.open("bit %s", name)
+
+			w.printf("position %d;", tp.Enum[name])
+
+			w.close()
+		}
+
+		w.close()
+
+		return
+	}
+
+	if full_range, ok := smi_full_ranges[tp.BaseType]; len(tp.Ranges) == 0 || smi_unrefinable_types[tp.Name] || (ok && len(tp.Ranges) == 1 && tp.Ranges[0] == full_range) {
+		w.printf("type %s;", yang_type)
+
+		return
+	}
+
+	var values []string
+
+	for _, rg := range tp.Ranges {
+		values = append(values, smi_range_value(rg))
+	}
+
+	restriction := "range"
+
+	if tp.BaseType == "OctetString" {
+		restriction = "length"
+	}
+
+	w.open("type %s", yang_type)
+
+	w.printf("%s %s;", restriction, yang_quote(strings.Join(values, " | ")))
+
+	w.close()
+}
+
+func (w *yang_writer) write_type(tp *OMFType) {
+	if tp == nil {
+		w.printf("type binary;")
+
+		return
+	}
+
+	if tp.Decl != "ImplicitType" && tp.Name != "" {
+		owner := w.type_owners[tp.Name]
+
+		if yang_type, ok := yang_well_known_types[owner+"::"+tp.Name]; ok {
+			if prefix, _, found := strings.Cut(yang_type, ":"); found {
+				w.require(yang_prefix_modules[prefix])
+			}
+
+			w.printf("type %s;", yang_type)
+
+			return
+		}
+
+		if owner == w.module.Name {
+			w.printf("type %s;", tp.Name)
+
+			return
+		}
+
+		if owner != "" && smi_base_type_modules[tp.Name] == "" {
+			w.printf("type %s:%s;", w.require(owner), tp.Name)
+
+			return
+		}
+	}
+
+	w.write_restrictions(tp, w.base_type(tp))
+}
+
+func (w *yang_writer) write_common(status string, description string, oid string) {
+	if yang_status(status) != "" {
+		w.printf("status %s;", yang_status(status))
+	}
+
+	if description != "" {
+		w.printf("description\n%s  %s;", strings.Repeat("  ", w.indent), yang_quote(description))
+	}
+
+	if oid != "" {
+		w.require("ietf-yang-smiv2")
+
+		w.printf("smiv2:oid %s;", yang_quote(oid))
+	}
+}
+
+func (w *yang_writer) write_typedef(tp *OMFType) {
+	if !smi_identifier_regex.MatchString(tp.Name) {
+		return
+	}
+
+	w.open("typedef %s", tp.Name)
+
+	w.write_restrictions(&OMFType{BaseType: tp.BaseType, Enum: tp.Enum, Ranges: tp.Ranges, Format: tp.Format}, w.base_type(&OMFType{BaseType: tp.BaseType, Format: tp.Format}))
+
+	if tp.Units != "" {
+		w.printf("units %s;", yang_quote(tp.Units))
+	}
+
+	w.write_common(tp.Status, tp.Description, "")
+
+	if tp.Format != "" {
+		w.printf("smiv2:display-hint %s;", yang_quote(tp.Format))
+	}
+
+	if tp.Reference != "" {
+		w.printf("reference %s;", yang_quote(tp.Reference))
+	}
+
+	w.close()
+}
+
+func (w *yang_writer) write_alias(nd *OMFNode) {
+	if !smi_identifier_regex.MatchString(nd.Name) || nd.Oid == "" {
+		return
+	}
+
+	w.require("ietf-yang-smiv2")
+
+	w.open("smiv2:alias %s", yang_quote(nd.Name))
+
+	if nd.Description != "" {
+		w.printf("description %s;", yang_quote(nd.Description))
+	}
+
+	w.printf("smiv2:oid %s;", yang_quote(nd.Oid))
+
+	w.close()
+}
+
+func (w *yang_writer) write_leaf(nd *OMFNode) {
+	w.open("leaf %s", nd.Name)
+
+	w.write_type(nd.Type)
+
+	if nd.Type != nil && nd.Type.Units != "" {
+		w.printf("units %s;", yang_quote(nd.Type.Units))
+	}
+
+	w.write_common(nd.Status, nd.Description, "")
+
+	w.require("ietf-yang-smiv2")
+
+	w.printf("smiv2:max-access %s;", yang_quote(smi_access(nd.Access)))
+
+	w.printf("smiv2:oid %s;", yang_quote(nd.Oid))
+
+	w.close()
+}
+
+func (w *yang_writer) write_leafref(name string, path string) {
+	w.open("leaf %s", name)
+
+	w.open("type leafref")
+
+	w.printf("path %s;", yang_quote(path))
+
+	w.close()
+
+	w.close()
+}
+
+func (w *yang_writer) write_scalars() {
+	var containers []string
+
+	scalars := make(map[string][]OMFNode)
+
+	for _, sc := range w.module.Scalars {
+		container := w.scalar_container(&sc.OMFNode)
+
+		if _, ok := scalars[container]; !ok {
+			containers = append(containers, container)
+		}
+
+		scalars[container] = append(scalars[container], sc.OMFNode)
+	}
+
+	for _, container := range containers {
+		w.open("container %s", container)
+
+		arcs := split_oid_arcs(scalars[container][0].Oid)
+
+		w.write_common("", "", strings.Join(arcs[:len(arcs)-1], "."))
+
+		for _, sc := range scalars[container] {
+			w.write_leaf(&sc)
+		}
+
+		w.close()
+	}
+}
+
+func (w *yang_writer) write_table(tb *OMFTable) {
+	if !smi_identifier_regex.MatchString(tb.Entry.Name) {
+		return
+	}
+
+	w.open("container %s", tb.Name)
+
+	w.write_common(tb.Status, tb.Description, tb.Oid)
+
+	w.open("list %s", tb.Entry.Name)
+
+	if len(tb.Indexes) > 0 {
+		w.printf("key %s;", yang_quote(omf_index_names(tb.Indexes)))
+	}
+
+	w.write_common(tb.Entry.Status, tb.Entry.Description, tb.Entry.Oid)
+
+	columns := make(map[string]bool)
+
+	for _, col := range tb.Columns {
+		columns[col.Name] = true
+	}
+
+	for _, idx := range tb.Indexes {
+		if columns[idx.Name] {
+			continue
+		}
+
+		if path, ok := w.leaf_paths[idx.Name]; ok {
+			w.write_leafref(idx.Name, path)
+		} else {
+			w.write_leaf(&idx.OMFNode)
+		}
+	}
+
+	for _, col := range tb.Columns {
+		w.write_leaf(&col)
+	}
+
+	w.close()
+
+	w.close()
+}
+
+func (w *yang_writer) write_notification(nf *OMFNotification) {
+	w.open("notification %s", nf.Name)
+
+	w.write_common(nf.Status, nf.Description, nf.Oid)
+
+	for idx, obj := range nf.Objects {
+		w.open("container object-%d", idx+1)
+
+		for _, key := range w.leaf_keys[obj.Name] {
+			if key != obj.Name {
+				w.write_leafref(key, w.leaf_paths[key])
+			}
+		}
+
+		if path, ok := w.leaf_paths[obj.Name]; ok {
+			w.write_leafref(obj.Name, path)
+		} else {
+			w.write_leaf(&obj)
+		}
+
+		w.close()
+	}
+
+	w.close()
+}
+
+func (w *yang_writer) write_header(out *strings.Builder) {
+	mod := w.module
+
+	fmt.Fprintf(out, "module %s {\n", mod.Name)
+
+	out.WriteString("  yang-version 1.1;\n")
+
+	fmt.Fprintf(out, "  namespace %s;\n", yang_quote("urn:ietf:params:xml:ns:yang:smiv2:"+mod.Name))
+
+	fmt.Fprintf(out, "  prefix %s;\n\n", yang_quote(w.prefix))
+
+	var modules []string
+
+	for module := range w.required {
+		modules = append(modules, module)
+	}
+
+	sort.Strings(modules)
+
+	for _, module := range modules {
+		prefix, ok := yang_imports[module]
+
+		if !ok {
+			prefix = yang_prefix(module)
+		}
+
+		fmt.Fprintf(out, "  import %s {\n    prefix %s;\n  }\n", module, yang_quote(prefix))
+	}
+
+	if len(modules) > 0 {
+		out.WriteString("\n")
+	}
+
+	if mod.Organization != "" {
+		fmt.Fprintf(out, "  organization\n    %s;\n\n", yang_quote(mod.Organization))
+	}
+
+	if mod.ContactInfo != "" {
+		fmt.Fprintf(out, "  contact\n    %s;\n\n", yang_quote(mod.ContactInfo))
+	}
+
+	if mod.Description != "" {
+		fmt.Fprintf(out, "  description\n    %s;\n\n", yang_quote(mod.Description))
+	}
+
+	revisions := append([]OMFRevision{}, mod.Revisions...)
+
+	sort.SliceStable(revisions, func(i, j int) bool {
+		return revisions[i].Date.After(revisions[j].Date)
+	})
+
+	revision_dates := make(map[string]bool)
+
+	for _, rev := range revisions {
+		date := rev.Date.UTC().Format("2006-01-02")
+
+		if revision_dates[date] {
+			continue
+		}
+
+		revision_dates[date] = true
+
+		fmt.Fprintf(out, "  revision %s {\n    description\n      %s;\n  }\n\n", date, yang_quote(rev.Description))
+	}
+}
+
+func render_yang_module(mod *OMFModule) string {
+	w := new_yang_writer(mod)
+
+	w.indent = 1
+
+	if mod.IdentityName != "" && mod.IdentityOid != "" {
+		w.write_alias(&OMFNode{Name: mod.IdentityName, Oid: mod.IdentityOid})
+	}
+
+	for _, nd := range mod.OtherNodes {
+		if nd.Kind == "Node" && nd.Name != mod.IdentityName {
+			w.write_alias(&nd)
+		}
+	}
+
+	for _, tp := range mod.Types {
+		w.write_typedef(&tp)
+	}
+
+	if len(mod.Scalars) > 0 || len(mod.Tables) > 0 {
+		w.open("container %s", mod.Name)
+
+		w.printf("config false;")
+
+		w.write_scalars()
+
+		for _, tb := range mod.Tables {
+			w.write_table(&tb)
+		}
+
+		w.close()
+	}
+
+	for _, nf := range mod.Notifications {
+		w.write_notification(&nf)
+	}
+
+	var out strings.Builder
+
+	w.write_header(&out)
+
+	out.WriteString(w.body.String())
+
+	out.WriteString("}\n")
+
+	return out.String()
+}
+
+func GenerateYangModule(mod OMFModule) (string, error) {
+	if !smi_identifier_regex.MatchString(mod.Name) {
+		return "", errors.New("module name is not a valid YANG module identifier")
+	}
+
+	return render_yang_module(&mod), nil
+}
+
+func WriteYangModule(out io.Writer, mod OMFModule) error {
+	source, err := GenerateYangModule(mod)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(out, source)
+
+	return err
+}
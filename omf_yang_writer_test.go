@@ -0,0 +1,48 @@
+package omifier
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateYangModule(t *testing.T) {
+	mod, err := GetOmfCommomStruct(omf_testdata_path, "ACME-TEST-MIB", false)
+
+	if err != nil {
+		t.Fatalf("GetOmfCommomStruct: %s", err)
+	}
+
+	source, err := GenerateYangModule(mod)
+
+	if err != nil {
+		t.Fatalf("GenerateYangModule: %s", err)
+	}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"header", "module ACME-TEST-MIB {\n  yang-version 1.1;\n"},
+		{"namespace", `namespace "urn:ietf:params:xml:ns:yang:smiv2:ACME-TEST-MIB";`},
+		{"revision", "revision 2024-01-15 {"},
+		{"typedef", "typedef AcmePortState {\n    type enumeration {\n      enum down {\n        value 0;"},
+		{"deprecated typedef", "typedef AcmeLegacyId {\n    type int32 {\n      range \"1..65535\";\n    }\n    status deprecated;"},
+		{"scalar oid", `smiv2:oid "1.3.6.1.4.1.99999.1.2";`},
+		{"scalar units", "type yang:gauge32;\n        units \"seconds\";"},
+		{"table list", "list acmePortEntry {\n        key \"acmePortIndex\";"},
+		{"imported tc", "type snmpv2-tc:RowStatus;"},
+		{"mac address", "type yang:mac-address;"},
+		{"notification", "notification acmePortDown {"},
+		{"notification object", "/acme-test-mib:ACME-TEST-MIB/acme-test-mib:acmePortTable/acme-test-mib:acmePortEntry/acme-test-mib:acmePortState"},
+	}
+
+	for _, tt := range tests {
+		if !strings.Contains(source, tt.want) {
+			t.Errorf("%s: YANG module has no %q", tt.name, tt.want)
+		}
+	}
+
+	if strings.Count(source, "{") != strings.Count(source, "}") {
+		t.Errorf("YANG module has unbalanced braces")
+	}
+}
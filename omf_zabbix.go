@@ -0,0 +1,317 @@
+package omifier
+
+import (
+	"crypto/md5"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+type OMFZabbixName struct {
+	Name string `yaml:"name"`
+}
+
+type OMFZabbixPreprocessing struct {
+	Type       string   `yaml:"type"`
+	Parameters []string `yaml:"parameters,omitempty"`
+}
+
+type OMFZabbixItem struct {
+	Uuid          string                   `yaml:"uuid"`
+	Name          string                   `yaml:"name"`
+	Type          string                   `yaml:"type"`
+	SnmpOid       string                   `yaml:"snmp_oid,omitempty"`
+	Key           string                   `yaml:"key"`
+	Delay         string                   `yaml:"delay,omitempty"`
+	ValueType     string                   `yaml:"value_type"`
+	Units         string                   `yaml:"units,omitempty"`
+	Description   string                   `yaml:"description,omitempty"`
+	Valuemap      *OMFZabbixName           `yaml:"valuemap,omitempty"`
+	Preprocessing []OMFZabbixPreprocessing `yaml:"preprocessing,omitempty"`
+}
+
+type OMFZabbixDiscoveryRule struct {
+	Uuid           string          `yaml:"uuid"`
+	Name           string          `yaml:"name"`
+	Type           string          `yaml:"type"`
+	SnmpOid        string          `yaml:"snmp_oid"`
+	Key            string          `yaml:"key"`
+	Delay          string          `yaml:"delay"`
+	Description    string          `yaml:"description,omitempty"`
+	ItemPrototypes []OMFZabbixItem `yaml:"item_prototypes"`
+}
+
+type OMFZabbixValuemapMapping struct {
+	Value    string `yaml:"value"`
+	Newvalue string `yaml:"newvalue"`
+}
+
+type OMFZabbixValuemap struct {
+	Uuid     string                     `yaml:"uuid"`
+	Name     string                     `yaml:"name"`
+	Mappings []OMFZabbixValuemapMapping `yaml:"mappings"`
+}
+
+type OMFZabbixTemplate struct {
+	Uuid           string                   `yaml:"uuid"`
+	Template       string                   `yaml:"template"`
+	Name           string                   `yaml:"name"`
+	Description    string                   `yaml:"description,omitempty"`
+	Groups         []OMFZabbixName          `yaml:"groups"`
+	Items          []OMFZabbixItem          `yaml:"items,omitempty"`
+	DiscoveryRules []OMFZabbixDiscoveryRule `yaml:"discovery_rules,omitempty"`
+	Valuemaps      []OMFZabbixValuemap      `yaml:"valuemaps,omitempty"`
+}
+
+type OMFZabbixGroup struct {
+	Uuid string `yaml:"uuid"`
+	Name string `yaml:"name"`
+}
+
+type OMFZabbixExport struct {
+	ZabbixExport struct {
+		Version   string              `yaml:"version"`
+		Groups    []OMFZabbixGroup    `yaml:"groups"`
+		Templates []OMFZabbixTemplate `yaml:"templates"`
+	} `yaml:"zabbix_export"`
+}
+
+var zabbix_template_group = "Templates/SNMP"
+
+var zabbix_macro_regex = regexp.MustCompile(`[^A-Z0-9_.]`)
+
+func zabbix_uuid(parts ...string) string {
+	sum := md5.Sum([]byte(strings.Join(parts, "::")))
+
+	sum[6] = (sum[6] & 0x0f) | 0x40
+
+	sum[8] = (sum[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x", sum)
+}
+
+func zabbix_macro(name string) string {
+	return "{#" + zabbix_macro_regex.ReplaceAllString(strings.ToUpper(name), "_") + "}"
+}
+
+type zabbix_template_builder struct {
+	module    *OMFModule
+	template  OMFZabbixTemplate
+	valuemaps map[string]bool
+}
+
+func (b *zabbix_template_builder) valuemap(nd *OMFNode) *OMFZabbixName {
+	tp := nd.Type
+
+	if tp == nil || tp.BaseType != "Enum" || len(tp.Enum) == 0 {
+		return nil
+	}
+
+	name := tp.Name
+
+	if tp.Decl == "ImplicitType" || name == "" {
+		name = nd.Name
+	}
+
+	if !b.valuemaps[name] {
+		b.valuemaps[name] = true
+
+		valuemap := OMFZabbixValuemap{
+			Uuid: zabbix_uuid(b.module.Name, "valuemap", name),
+			Name: name,
+		}
+
+		for _, label := range smi_ordered_enum(tp.Enum) {
+			valuemap.Mappings = append(valuemap.Mappings, OMFZabbixValuemapMapping{
+				Value:    fmt.Sprintf("%d", tp.Enum[label]),
+				Newvalue: label,
+			})
+		}
+
+		b.template.Valuemaps = append(b.template.Valuemaps, valuemap)
+	}
+
+	return &OMFZabbixName{Name: name}
+}
+
+func (b *zabbix_template_builder) item(nd *OMFNode, name string, key string, snmp_oid string) (OMFZabbixItem, bool) {
+	if nd.Access == "NotAccessible" || nd.Access == "Notify" || nd.Type == nil {
+		return OMFZabbixItem{}, false
+	}
+
+	item := OMFZabbixItem{
+		Uuid:        zabbix_uuid(b.module.Name, "item", key),
+		Name:        name,
+		Type:        "SNMP_AGENT",
+		SnmpOid:     snmp_oid,
+		Key:         key,
+		ValueType:   "UNSIGNED",
+		Units:       nd.Type.Units,
+		Description: normalize_smi_text(nd.Description),
+		Valuemap:    b.valuemap(nd),
+	}
+
+	switch {
+	case nd.Type.BaseType == "OctetString" || nd.Type.BaseType == "ObjectIdentifier" || nd.Type.BaseType == "Bits":
+		item.ValueType = "CHAR"
+
+		item.Delay = "1h"
+	case nd.Type.Name == "TimeTicks":
+		item.ValueType = "FLOAT"
+
+		item.Units = "uptime"
+
+		item.Preprocessing = append(item.Preprocessing, OMFZabbixPreprocessing{Type: "MULTIPLIER", Parameters: []string{"0.01"}})
+	case strings.Contains(nd.Type.Name, "Counter"):
+		item.Preprocessing = append(item.Preprocessing, OMFZabbixPreprocessing{Type: "CHANGE_PER_SECOND", Parameters: []string{""}})
+	case nd.Type.BaseType == "Integer32" || nd.Type.BaseType == "Integer64":
+		item.ValueType = "FLOAT"
+	}
+
+	return item, true
+}
+
+func (b *zabbix_template_builder) add_scalars() {
+	for _, sc := range b.module.Scalars {
+		item, ok := b.item(&sc.OMFNode, sc.Name, sc.Name, sc.Oid+".0")
+
+		if ok {
+			b.template.Items = append(b.template.Items, item)
+		}
+	}
+}
+
+func (b *zabbix_template_builder) add_table(tb *OMFTable) {
+	var discovery_pairs []string
+
+	label := "{#SNMPINDEX}"
+
+	for _, col := range tb.Columns {
+		if col.Access == "NotAccessible" || snmp_exporter_type(col.Type, nil) != "DisplayString" {
+			continue
+		}
+
+		discovery_pairs = append(discovery_pairs, zabbix_macro(col.Name)+","+col.Oid)
+	}
+
+	if descr, ok := snmp_exporter_descriptive_column(tb); ok {
+		label = zabbix_macro(descr.Name)
+	}
+
+	rule := OMFZabbixDiscoveryRule{
+		Uuid:        zabbix_uuid(b.module.Name, "discovery", tb.Name),
+		Name:        tb.Name + " discovery",
+		Type:        "SNMP_AGENT",
+		Key:         tb.Name + ".discovery",
+		Delay:       "1h",
+		Description: normalize_smi_text(tb.Description),
+	}
+
+	for _, col := range tb.Columns {
+		key := col.Name + "[{#SNMPINDEX}]"
+
+		item, ok := b.item(&col, col.Name+" on "+label, key, col.Oid+".{#SNMPINDEX}")
+
+		if !ok {
+			continue
+		}
+
+		if len(discovery_pairs) == 0 {
+			discovery_pairs = append(discovery_pairs, zabbix_macro(col.Name)+","+col.Oid)
+		}
+
+		rule.ItemPrototypes = append(rule.ItemPrototypes, item)
+	}
+
+	if len(rule.ItemPrototypes) == 0 {
+		return
+	}
+
+	rule.SnmpOid = "discovery[" + strings.Join(discovery_pairs, ",") + "]"
+
+	b.template.DiscoveryRules = append(b.template.DiscoveryRules, rule)
+}
+
+func (b *zabbix_template_builder) add_notifications() {
+	for _, nf := range b.module.Notifications {
+		oid_pattern := strings.ReplaceAll(nf.Oid, ".", "\\.")
+
+		key := fmt.Sprintf("snmptrap[\"%s|%s\"]", nf.Name, oid_pattern)
+
+		b.template.Items = append(b.template.Items, OMFZabbixItem{
+			Uuid:        zabbix_uuid(b.module.Name, "trap", nf.Name),
+			Name:        nf.Name + " trap",
+			Type:        "SNMP_TRAP",
+			Key:         key,
+			ValueType:   "LOG",
+			Description: normalize_smi_text(nf.Description),
+		})
+	}
+}
+
+func create_zabbix_template(mod *OMFModule) OMFZabbixTemplate {
+	b := zabbix_template_builder{
+		module:    mod,
+		valuemaps: make(map[string]bool),
+		template: OMFZabbixTemplate{
+			Uuid:        zabbix_uuid(mod.Name, "template"),
+			Template:    mod.Name + " by SNMP",
+			Name:        mod.Name + " by SNMP",
+			Description: normalize_smi_text(mod.Description),
+			Groups:      []OMFZabbixName{{Name: zabbix_template_group}},
+		},
+	}
+
+	b.add_scalars()
+
+	for _, tb := range mod.Tables {
+		b.add_table(&tb)
+	}
+
+	b.add_notifications()
+
+	return b.template
+}
+
+func GenerateZabbixTemplate(mods ...OMFModule) (OMFZabbixExport, error) {
+	export := OMFZabbixExport{}
+
+	export.ZabbixExport.Version = "6.0"
+
+	export.ZabbixExport.Groups = []OMFZabbixGroup{{
+		Uuid: zabbix_uuid("group", zabbix_template_group),
+		Name: zabbix_template_group,
+	}}
+
+	for _, mod := range mods {
+		if mod.Name == "" {
+			return export, errors.New("cannot generate a zabbix template for an unnamed module")
+		}
+
+		export.ZabbixExport.Templates = append(export.ZabbixExport.Templates, create_zabbix_template(&mod))
+	}
+
+	return export, nil
+}
+
+func WriteZabbixTemplate(out io.Writer, mods ...OMFModule) error {
+	export, err := GenerateZabbixTemplate(mods...)
+
+	if err != nil {
+		return err
+	}
+
+	encoded, err := yaml.Marshal(export)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = out.Write(encoded)
+
+	return err
+}
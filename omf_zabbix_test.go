@@ -0,0 +1,96 @@
+package omifier
+
+import (
+	"bytes"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func omf_zabbix_items_by_key(items []OMFZabbixItem) map[string]OMFZabbixItem {
+	by_key := make(map[string]OMFZabbixItem)
+
+	for _, item := range items {
+		by_key[item.Key] = item
+	}
+
+	return by_key
+}
+
+func TestWriteZabbixTemplate(t *testing.T) {
+	mod, err := GetOmfCommomStruct(omf_testdata_path, "ACME-TEST-MIB", false)
+
+	if err != nil {
+		t.Fatalf("GetOmfCommomStruct: %s", err)
+	}
+
+	var out bytes.Buffer
+
+	err = WriteZabbixTemplate(&out, mod)
+
+	if err != nil {
+		t.Fatalf("WriteZabbixTemplate: %s", err)
+	}
+
+	var export OMFZabbixExport
+
+	err = yaml.UnmarshalStrict(out.Bytes(), &export)
+
+	if err != nil {
+		t.Fatalf("zabbix template does not decode: %s", err)
+	}
+
+	if len(export.ZabbixExport.Templates) != 1 {
+		t.Fatalf("zabbix export has %d templates", len(export.ZabbixExport.Templates))
+	}
+
+	template := export.ZabbixExport.Templates[0]
+
+	items := omf_zabbix_items_by_key(template.Items)
+
+	if item := items["acmeUptime"]; item.SnmpOid != "1.3.6.1.4.1.99999.1.2.0" || item.Units != "seconds" || item.ValueType != "UNSIGNED" {
+		t.Errorf("acmeUptime item = %+v", item)
+	}
+
+	if item := items[`snmptrap["acmePortDown|1\.3\.6\.1\.4\.1\.99999\.2\.1"]`]; item.Type != "SNMP_TRAP" {
+		t.Errorf("acmePortDown has no trap item: %+v", template.Items)
+	}
+
+	if len(template.DiscoveryRules) != 1 {
+		t.Fatalf("template has %d discovery rules", len(template.DiscoveryRules))
+	}
+
+	rule := template.DiscoveryRules[0]
+
+	if rule.SnmpOid != "discovery[{#ACMEPORTNAME},1.3.6.1.4.1.99999.1.10.1.2]" {
+		t.Errorf("acmePortTable discovery walks %s", rule.SnmpOid)
+	}
+
+	prototypes := omf_zabbix_items_by_key(rule.ItemPrototypes)
+
+	if item := prototypes["acmePortInOctets[{#SNMPINDEX}]"]; item.SnmpOid != "1.3.6.1.4.1.99999.1.10.1.5.{#SNMPINDEX}" || len(item.Preprocessing) != 1 || item.Preprocessing[0].Type != "CHANGE_PER_SECOND" {
+		t.Errorf("acmePortInOctets prototype = %+v", item)
+	}
+
+	if item := prototypes["acmePortState[{#SNMPINDEX}]"]; item.Valuemap == nil || item.Valuemap.Name != "AcmePortState" {
+		t.Errorf("acmePortState prototype = %+v", item)
+	}
+
+	valuemaps := make(map[string]OMFZabbixValuemap)
+
+	for _, valuemap := range template.Valuemaps {
+		valuemaps[valuemap.Name] = valuemap
+	}
+
+	if mappings := valuemaps["AcmePortState"].Mappings; len(mappings) != 3 || mappings[1] != (OMFZabbixValuemapMapping{Value: "1", Newvalue: "up"}) {
+		t.Errorf("AcmePortState value map = %+v", mappings)
+	}
+
+	var again bytes.Buffer
+
+	err = WriteZabbixTemplate(&again, mod)
+
+	if err != nil || again.String() != out.String() {
+		t.Errorf("zabbix template is not stable across runs")
+	}
+}